@@ -0,0 +1,409 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: api/proto/admin_service.proto
+
+package job_service
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AdminService_RequeueJob_FullMethodName        = "/job_service.AdminService/RequeueJob"
+	AdminService_FailJob_FullMethodName           = "/job_service.AdminService/FailJob"
+	AdminService_DrainAgent_FullMethodName        = "/job_service.AdminService/DrainAgent"
+	AdminService_EvictAgent_FullMethodName        = "/job_service.AdminService/EvictAgent"
+	AdminService_ListAgents_FullMethodName        = "/job_service.AdminService/ListAgents"
+	AdminService_PauseScheduler_FullMethodName    = "/job_service.AdminService/PauseScheduler"
+	AdminService_ResumeScheduler_FullMethodName   = "/job_service.AdminService/ResumeScheduler"
+	AdminService_GetSchedulerState_FullMethodName = "/job_service.AdminService/GetSchedulerState"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AdminService exposes operator actions on jobs, agents and the scheduler.
+type AdminServiceClient interface {
+	// RequeueJob puts a job back to PENDING so the scheduler picks it up again.
+	RequeueJob(ctx context.Context, in *RequeueJobRequest, opts ...grpc.CallOption) (*AdminJobResponse, error)
+	// FailJob marks a job FAILED with an operator-supplied reason.
+	FailJob(ctx context.Context, in *FailJobRequest, opts ...grpc.CallOption) (*AdminJobResponse, error)
+	// DrainAgent stops an agent from receiving new jobs.
+	DrainAgent(ctx context.Context, in *AdminAgentRequest, opts ...grpc.CallOption) (*AdminAgentResponse, error)
+	// EvictAgent takes an agent offline and requeues the jobs assigned to it.
+	EvictAgent(ctx context.Context, in *AdminAgentRequest, opts ...grpc.CallOption) (*AdminAgentResponse, error)
+	// ListAgents lists registered agents, leaving out OFFLINE ones unless
+	// asked for them.
+	ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error)
+	// PauseScheduler stops all scheduler instances from grouping new jobs.
+	PauseScheduler(ctx context.Context, in *PauseSchedulerRequest, opts ...grpc.CallOption) (*SchedulerStateResponse, error)
+	// ResumeScheduler undoes PauseScheduler.
+	ResumeScheduler(ctx context.Context, in *ResumeSchedulerRequest, opts ...grpc.CallOption) (*SchedulerStateResponse, error)
+	// GetSchedulerState dumps the state of the scheduler on this instance.
+	GetSchedulerState(ctx context.Context, in *GetSchedulerStateRequest, opts ...grpc.CallOption) (*SchedulerStateResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) RequeueJob(ctx context.Context, in *RequeueJobRequest, opts ...grpc.CallOption) (*AdminJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminJobResponse)
+	err := c.cc.Invoke(ctx, AdminService_RequeueJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) FailJob(ctx context.Context, in *FailJobRequest, opts ...grpc.CallOption) (*AdminJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminJobResponse)
+	err := c.cc.Invoke(ctx, AdminService_FailJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) DrainAgent(ctx context.Context, in *AdminAgentRequest, opts ...grpc.CallOption) (*AdminAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminAgentResponse)
+	err := c.cc.Invoke(ctx, AdminService_DrainAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) EvictAgent(ctx context.Context, in *AdminAgentRequest, opts ...grpc.CallOption) (*AdminAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminAgentResponse)
+	err := c.cc.Invoke(ctx, AdminService_EvictAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAgentsResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListAgents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) PauseScheduler(ctx context.Context, in *PauseSchedulerRequest, opts ...grpc.CallOption) (*SchedulerStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerStateResponse)
+	err := c.cc.Invoke(ctx, AdminService_PauseScheduler_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ResumeScheduler(ctx context.Context, in *ResumeSchedulerRequest, opts ...grpc.CallOption) (*SchedulerStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerStateResponse)
+	err := c.cc.Invoke(ctx, AdminService_ResumeScheduler_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetSchedulerState(ctx context.Context, in *GetSchedulerStateRequest, opts ...grpc.CallOption) (*SchedulerStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerStateResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetSchedulerState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility.
+//
+// AdminService exposes operator actions on jobs, agents and the scheduler.
+type AdminServiceServer interface {
+	// RequeueJob puts a job back to PENDING so the scheduler picks it up again.
+	RequeueJob(context.Context, *RequeueJobRequest) (*AdminJobResponse, error)
+	// FailJob marks a job FAILED with an operator-supplied reason.
+	FailJob(context.Context, *FailJobRequest) (*AdminJobResponse, error)
+	// DrainAgent stops an agent from receiving new jobs.
+	DrainAgent(context.Context, *AdminAgentRequest) (*AdminAgentResponse, error)
+	// EvictAgent takes an agent offline and requeues the jobs assigned to it.
+	EvictAgent(context.Context, *AdminAgentRequest) (*AdminAgentResponse, error)
+	// ListAgents lists registered agents, leaving out OFFLINE ones unless
+	// asked for them.
+	ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error)
+	// PauseScheduler stops all scheduler instances from grouping new jobs.
+	PauseScheduler(context.Context, *PauseSchedulerRequest) (*SchedulerStateResponse, error)
+	// ResumeScheduler undoes PauseScheduler.
+	ResumeScheduler(context.Context, *ResumeSchedulerRequest) (*SchedulerStateResponse, error)
+	// GetSchedulerState dumps the state of the scheduler on this instance.
+	GetSchedulerState(context.Context, *GetSchedulerStateRequest) (*SchedulerStateResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServiceServer struct{}
+
+func (UnimplementedAdminServiceServer) RequeueJob(context.Context, *RequeueJobRequest) (*AdminJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueJob not implemented")
+}
+func (UnimplementedAdminServiceServer) FailJob(context.Context, *FailJobRequest) (*AdminJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FailJob not implemented")
+}
+func (UnimplementedAdminServiceServer) DrainAgent(context.Context, *AdminAgentRequest) (*AdminAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainAgent not implemented")
+}
+func (UnimplementedAdminServiceServer) EvictAgent(context.Context, *AdminAgentRequest) (*AdminAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvictAgent not implemented")
+}
+func (UnimplementedAdminServiceServer) ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAgents not implemented")
+}
+func (UnimplementedAdminServiceServer) PauseScheduler(context.Context, *PauseSchedulerRequest) (*SchedulerStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseScheduler not implemented")
+}
+func (UnimplementedAdminServiceServer) ResumeScheduler(context.Context, *ResumeSchedulerRequest) (*SchedulerStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeScheduler not implemented")
+}
+func (UnimplementedAdminServiceServer) GetSchedulerState(context.Context, *GetSchedulerStateRequest) (*SchedulerStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSchedulerState not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_RequeueJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).RequeueJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_RequeueJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).RequeueJob(ctx, req.(*RequeueJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_FailJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FailJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).FailJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_FailJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).FailJob(ctx, req.(*FailJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_DrainAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).DrainAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_DrainAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).DrainAgent(ctx, req.(*AdminAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_EvictAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).EvictAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_EvictAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).EvictAgent(ctx, req.(*AdminAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ListAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListAgents(ctx, req.(*ListAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_PauseScheduler_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseSchedulerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).PauseScheduler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_PauseScheduler_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).PauseScheduler(ctx, req.(*PauseSchedulerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ResumeScheduler_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeSchedulerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ResumeScheduler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ResumeScheduler_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ResumeScheduler(ctx, req.(*ResumeSchedulerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetSchedulerState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSchedulerStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetSchedulerState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetSchedulerState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetSchedulerState(ctx, req.(*GetSchedulerStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "job_service.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequeueJob",
+			Handler:    _AdminService_RequeueJob_Handler,
+		},
+		{
+			MethodName: "FailJob",
+			Handler:    _AdminService_FailJob_Handler,
+		},
+		{
+			MethodName: "DrainAgent",
+			Handler:    _AdminService_DrainAgent_Handler,
+		},
+		{
+			MethodName: "EvictAgent",
+			Handler:    _AdminService_EvictAgent_Handler,
+		},
+		{
+			MethodName: "ListAgents",
+			Handler:    _AdminService_ListAgents_Handler,
+		},
+		{
+			MethodName: "PauseScheduler",
+			Handler:    _AdminService_PauseScheduler_Handler,
+		},
+		{
+			MethodName: "ResumeScheduler",
+			Handler:    _AdminService_ResumeScheduler_Handler,
+		},
+		{
+			MethodName: "GetSchedulerState",
+			Handler:    _AdminService_GetSchedulerState_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/proto/admin_service.proto",
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.4.0
+// - protoc-gen-go-grpc v1.5.1
 // - protoc             v5.29.3
 // source: api/proto/job_service.proto
 
@@ -15,15 +15,18 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.62.0 or later.
-const _ = grpc.SupportPackageIsVersion8
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
 
 const (
 	JobService_SubmitJob_FullMethodName       = "/job_service.JobService/SubmitJob"
 	JobService_GetJobStatus_FullMethodName    = "/job_service.JobService/GetJobStatus"
+	JobService_GetJobGroup_FullMethodName     = "/job_service.JobService/GetJobGroup"
 	JobService_RegisterAgent_FullMethodName   = "/job_service.JobService/RegisterAgent"
+	JobService_DeregisterAgent_FullMethodName = "/job_service.JobService/DeregisterAgent"
 	JobService_UpdateJobStatus_FullMethodName = "/job_service.JobService/UpdateJobStatus"
 	JobService_FetchJob_FullMethodName        = "/job_service.JobService/FetchJob"
+	JobService_AgentSession_FullMethodName    = "/job_service.JobService/AgentSession"
 )
 
 // JobServiceClient is the client API for JobService service.
@@ -36,12 +39,21 @@ type JobServiceClient interface {
 	SubmitJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*SubmitJobResponse, error)
 	// GetJobStatus retrieves the status of a job.
 	GetJobStatus(ctx context.Context, in *GetJobStatusRequest, opts ...grpc.CallOption) (*GetJobStatusResponse, error)
+	// GetJobGroup retrieves a job group, its rolled-up status and its jobs.
+	GetJobGroup(ctx context.Context, in *GetJobGroupRequest, opts ...grpc.CallOption) (*GetJobGroupResponse, error)
 	// RegisterAgent allows an agent to register with the orchestrator.
 	RegisterAgent(ctx context.Context, in *RegisterAgentRequest, opts ...grpc.CallOption) (*RegisterAgentResponse, error)
+	// DeregisterAgent takes an agent offline as it shuts down, handing back
+	// any jobs it hadn't finished.
+	DeregisterAgent(ctx context.Context, in *DeregisterAgentRequest, opts ...grpc.CallOption) (*DeregisterAgentResponse, error)
 	// UpdateJobStatus is used by an agent to report job progress.
 	UpdateJobStatus(ctx context.Context, in *UpdateJobStatusRequest, opts ...grpc.CallOption) (*UpdateJobStatusResponse, error)
 	// FetchJob fetches a job for a given target capability.
 	FetchJob(ctx context.Context, in *FetchJobRequest, opts ...grpc.CallOption) (*FetchJobResponse, error)
+	// AgentSession is a long-lived stream for a registered agent. The agent
+	// sends an AgentHello first, then heartbeats, capacity changes and job
+	// progress; the server pushes job group assignments and cancellations.
+	AgentSession(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AgentMessage, ServerMessage], error)
 }
 
 type jobServiceClient struct {
@@ -72,6 +84,16 @@ func (c *jobServiceClient) GetJobStatus(ctx context.Context, in *GetJobStatusReq
 	return out, nil
 }
 
+func (c *jobServiceClient) GetJobGroup(ctx context.Context, in *GetJobGroupRequest, opts ...grpc.CallOption) (*GetJobGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetJobGroupResponse)
+	err := c.cc.Invoke(ctx, JobService_GetJobGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *jobServiceClient) RegisterAgent(ctx context.Context, in *RegisterAgentRequest, opts ...grpc.CallOption) (*RegisterAgentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RegisterAgentResponse)
@@ -82,6 +104,16 @@ func (c *jobServiceClient) RegisterAgent(ctx context.Context, in *RegisterAgentR
 	return out, nil
 }
 
+func (c *jobServiceClient) DeregisterAgent(ctx context.Context, in *DeregisterAgentRequest, opts ...grpc.CallOption) (*DeregisterAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeregisterAgentResponse)
+	err := c.cc.Invoke(ctx, JobService_DeregisterAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *jobServiceClient) UpdateJobStatus(ctx context.Context, in *UpdateJobStatusRequest, opts ...grpc.CallOption) (*UpdateJobStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateJobStatusResponse)
@@ -102,9 +134,22 @@ func (c *jobServiceClient) FetchJob(ctx context.Context, in *FetchJobRequest, op
 	return out, nil
 }
 
+func (c *jobServiceClient) AgentSession(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AgentMessage, ServerMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &JobService_ServiceDesc.Streams[0], JobService_AgentSession_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AgentMessage, ServerMessage]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JobService_AgentSessionClient = grpc.BidiStreamingClient[AgentMessage, ServerMessage]
+
 // JobServiceServer is the server API for JobService service.
 // All implementations must embed UnimplementedJobServiceServer
-// for forward compatibility
+// for forward compatibility.
 //
 // JobService is the main service for managing jobs.
 type JobServiceServer interface {
@@ -112,18 +157,30 @@ type JobServiceServer interface {
 	SubmitJob(context.Context, *SubmitJobRequest) (*SubmitJobResponse, error)
 	// GetJobStatus retrieves the status of a job.
 	GetJobStatus(context.Context, *GetJobStatusRequest) (*GetJobStatusResponse, error)
+	// GetJobGroup retrieves a job group, its rolled-up status and its jobs.
+	GetJobGroup(context.Context, *GetJobGroupRequest) (*GetJobGroupResponse, error)
 	// RegisterAgent allows an agent to register with the orchestrator.
 	RegisterAgent(context.Context, *RegisterAgentRequest) (*RegisterAgentResponse, error)
+	// DeregisterAgent takes an agent offline as it shuts down, handing back
+	// any jobs it hadn't finished.
+	DeregisterAgent(context.Context, *DeregisterAgentRequest) (*DeregisterAgentResponse, error)
 	// UpdateJobStatus is used by an agent to report job progress.
 	UpdateJobStatus(context.Context, *UpdateJobStatusRequest) (*UpdateJobStatusResponse, error)
 	// FetchJob fetches a job for a given target capability.
 	FetchJob(context.Context, *FetchJobRequest) (*FetchJobResponse, error)
+	// AgentSession is a long-lived stream for a registered agent. The agent
+	// sends an AgentHello first, then heartbeats, capacity changes and job
+	// progress; the server pushes job group assignments and cancellations.
+	AgentSession(grpc.BidiStreamingServer[AgentMessage, ServerMessage]) error
 	mustEmbedUnimplementedJobServiceServer()
 }
 
-// UnimplementedJobServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedJobServiceServer struct {
-}
+// UnimplementedJobServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedJobServiceServer struct{}
 
 func (UnimplementedJobServiceServer) SubmitJob(context.Context, *SubmitJobRequest) (*SubmitJobResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SubmitJob not implemented")
@@ -131,16 +188,26 @@ func (UnimplementedJobServiceServer) SubmitJob(context.Context, *SubmitJobReques
 func (UnimplementedJobServiceServer) GetJobStatus(context.Context, *GetJobStatusRequest) (*GetJobStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetJobStatus not implemented")
 }
+func (UnimplementedJobServiceServer) GetJobGroup(context.Context, *GetJobGroupRequest) (*GetJobGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJobGroup not implemented")
+}
 func (UnimplementedJobServiceServer) RegisterAgent(context.Context, *RegisterAgentRequest) (*RegisterAgentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RegisterAgent not implemented")
 }
+func (UnimplementedJobServiceServer) DeregisterAgent(context.Context, *DeregisterAgentRequest) (*DeregisterAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeregisterAgent not implemented")
+}
 func (UnimplementedJobServiceServer) UpdateJobStatus(context.Context, *UpdateJobStatusRequest) (*UpdateJobStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateJobStatus not implemented")
 }
 func (UnimplementedJobServiceServer) FetchJob(context.Context, *FetchJobRequest) (*FetchJobResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FetchJob not implemented")
 }
+func (UnimplementedJobServiceServer) AgentSession(grpc.BidiStreamingServer[AgentMessage, ServerMessage]) error {
+	return status.Errorf(codes.Unimplemented, "method AgentSession not implemented")
+}
 func (UnimplementedJobServiceServer) mustEmbedUnimplementedJobServiceServer() {}
+func (UnimplementedJobServiceServer) testEmbeddedByValue()                    {}
 
 // UnsafeJobServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to JobServiceServer will
@@ -150,6 +217,13 @@ type UnsafeJobServiceServer interface {
 }
 
 func RegisterJobServiceServer(s grpc.ServiceRegistrar, srv JobServiceServer) {
+	// If the following call pancis, it indicates UnimplementedJobServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&JobService_ServiceDesc, srv)
 }
 
@@ -189,6 +263,24 @@ func _JobService_GetJobStatus_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobService_GetJobGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobServiceServer).GetJobGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobService_GetJobGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobServiceServer).GetJobGroup(ctx, req.(*GetJobGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _JobService_RegisterAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RegisterAgentRequest)
 	if err := dec(in); err != nil {
@@ -207,6 +299,24 @@ func _JobService_RegisterAgent_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobService_DeregisterAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeregisterAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobServiceServer).DeregisterAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobService_DeregisterAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobServiceServer).DeregisterAgent(ctx, req.(*DeregisterAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _JobService_UpdateJobStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateJobStatusRequest)
 	if err := dec(in); err != nil {
@@ -243,6 +353,13 @@ func _JobService_FetchJob_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobService_AgentSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(JobServiceServer).AgentSession(&grpc.GenericServerStream[AgentMessage, ServerMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JobService_AgentSessionServer = grpc.BidiStreamingServer[AgentMessage, ServerMessage]
+
 // JobService_ServiceDesc is the grpc.ServiceDesc for JobService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -258,10 +375,18 @@ var JobService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetJobStatus",
 			Handler:    _JobService_GetJobStatus_Handler,
 		},
+		{
+			MethodName: "GetJobGroup",
+			Handler:    _JobService_GetJobGroup_Handler,
+		},
 		{
 			MethodName: "RegisterAgent",
 			Handler:    _JobService_RegisterAgent_Handler,
 		},
+		{
+			MethodName: "DeregisterAgent",
+			Handler:    _JobService_DeregisterAgent_Handler,
+		},
 		{
 			MethodName: "UpdateJobStatus",
 			Handler:    _JobService_UpdateJobStatus_Handler,
@@ -271,6 +396,13 @@ var JobService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _JobService_FetchJob_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AgentSession",
+			Handler:       _JobService_AgentSession_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "api/proto/job_service.proto",
 }
@@ -3,39 +3,128 @@ package main
 import (
 	"context"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	"qualgent-test-platform/internal/agent"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/tracing"
 )
 
 func main() {
 	var (
-		serverAddr = flag.String("server", "localhost:8080", "gRPC server address")
-		hostname   = flag.String("hostname", "", "Agent hostname (defaults to system hostname)")
+		configPath  = flag.String("config", os.Getenv("QG_CONFIG"), "Path to a YAML config file (env QG_CONFIG)")
+		printConfig = flag.Bool("print-config", false, "Print the effective configuration and exit")
+		serverAddr  = flag.String("server", "", "gRPC server address (overrides config)")
+		hostname    = flag.String("hostname", "", "Agent hostname (defaults to system hostname)")
+		name        = flag.String("name", "", "Name telling this agent apart from others on its host (overrides config)")
+		metricsAddr = flag.String("metrics-addr", "", "Address to serve Prometheus metrics on (overrides config)")
+		logLevel    = flag.String("log-level", "", "Log level: debug, info, warn or error (overrides config)")
+		targets     = flag.String("targets", "", "Targets to run jobs for, e.g. browserstack,web (overrides config)")
+		slots       = flag.Int("slots", 0, "Number of job groups to run at once (overrides config)")
+		labels      = flag.String("labels", "", "Capability labels as key=value pairs, e.g. os_version=13,device=pixel7 (overrides config)")
 	)
 	flag.Parse()
 
+	// Load configuration: defaults, then the config file, then environment,
+	// then flags given on the command line
+	cfg, err := config.LoadAgent(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "server":
+			cfg.Server = *serverAddr
+		case "hostname":
+			cfg.Hostname = *hostname
+		case "name":
+			cfg.Name = *name
+		case "metrics-addr":
+			cfg.MetricsAddr = *metricsAddr
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		case "targets":
+			cfg.Targets = *targets
+		case "slots":
+			cfg.Slots = *slots
+		case "labels":
+			parsed, err := parseLabels(*labels)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid -labels: %v\n", err)
+				os.Exit(1)
+			}
+			cfg.Labels = parsed
+		}
+	})
+
 	// Get hostname if not provided
-	if *hostname == "" {
+	if cfg.Hostname == "" {
 		hostnameFromEnv, err := os.Hostname()
 		if err != nil {
-			log.Fatalf("Failed to get hostname: %v", err)
+			fmt.Fprintf(os.Stderr, "Failed to get hostname: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Hostname = hostnameFromEnv
+	}
+
+	if *printConfig {
+		if err := config.Print(os.Stdout, "agent", cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+			os.Exit(1)
 		}
-		*hostname = hostnameFromEnv
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	logging.Init("appwright-agent", logging.Options{
+		Level:            cfg.Log.Level,
+		Format:           cfg.Log.Format,
+		SampleInitial:    cfg.Log.SampleInitial,
+		SampleThereafter: cfg.Log.SampleThereafter,
+	})
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), "appwright-agent")
+	if err != nil {
+		logging.Fatal("Failed to initialize tracing", "error", err)
 	}
+	defer shutdownTracing(context.Background())
 
-	// Check required environment variables
-	if os.Getenv("BROWSERSTACK_USERNAME") == "" || os.Getenv("BROWSERSTACK_ACCESS_KEY") == "" {
-		log.Fatal("BROWSERSTACK_USERNAME and BROWSERSTACK_ACCESS_KEY environment variables are required")
+	executors, err := newExecutors(cfg)
+	if err != nil {
+		logging.Fatal("Failed to set up executors", "error", err)
 	}
 
 	// Create AppWright agent
-	agent, err := agent.NewAppWrightAgent(*serverAddr, *hostname)
+	agent, err := agent.NewAppWrightAgent(cfg, executors)
 	if err != nil {
-		log.Fatalf("Failed to create AppWright agent: %v", err)
+		logging.Fatal("Failed to create AppWright agent", "error", err)
+	}
+
+	// Expose Prometheus metrics
+	if cfg.MetricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			slog.Info("Metrics listening", "addr", cfg.MetricsAddr)
+			if err := http.ListenAndServe(cfg.MetricsAddr, mux); err != nil {
+				slog.Error("Failed to serve metrics", "error", err)
+			}
+		}()
 	}
 
 	// Create context for graceful shutdown
@@ -48,15 +137,51 @@ func main() {
 
 	go func() {
 		sig := <-sigChan
-		log.Printf("Received signal %v, shutting down...", sig)
+		slog.Info("Received signal, waiting for running tests", "signal", sig.String(), "timeout", cfg.ShutdownTimeout)
 		cancel()
+		sig = <-sigChan
+		slog.Warn("Received second signal, exiting now", "signal", sig.String())
+		os.Exit(1)
 	}()
 
 	// Start the agent
-	log.Printf("Starting AppWright agent on %s", *hostname)
+	slog.Info("Starting AppWright agent", "hostname", cfg.Hostname)
 	if err := agent.Start(ctx); err != nil {
-		log.Fatalf("Agent failed: %v", err)
+		logging.Fatal("Agent failed", "error", err)
+	}
+
+	slog.Info("AppWright agent stopped")
+}
+
+// newExecutors registers an executor for each of the agent's targets.
+func newExecutors(cfg config.Agent) (agent.Executors, error) {
+	executors := make(agent.Executors)
+	for _, target := range cfg.TargetList() {
+		switch target {
+		case "browserstack":
+			executors[target] = agent.NewBrowserStackExecutor(agent.NewBrowserStackClient(cfg.BrowserStack))
+		case "web", "emulator", "device":
+			executors[target] = agent.NewLocalExecutor(cfg.Local, target)
+		default:
+			return nil, fmt.Errorf("no executor for target %q", target)
+		}
 	}
+	return executors, nil
+}
 
-	log.Println("AppWright agent stopped")
-} 
\ No newline at end of file
+// parseLabels parses comma-separated key=value pairs.
+func parseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("label %q must be key=value", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"qualgent-test-platform/internal/agent/bstest"
+)
+
+// fake-browserstack serves a fake App Automate API, so that agents can run
+// BrowserStack jobs offline: point BROWSERSTACK_BASE_URL at it.
+func main() {
+	var (
+		addr            = flag.String("addr", ":8090", "Address to listen on")
+		username        = flag.String("username", "", "Basic auth username to require (any if empty)")
+		accessKey       = flag.String("access-key", "", "Basic auth access key to require (any if empty)")
+		queueDelay      = flag.Duration("queue-delay", time.Second, "How long each session stays queued")
+		sessionDuration = flag.Duration("session-duration", 5*time.Second, "How long each session runs")
+		failMatch       = flag.String("fail", "fail", "Fail sessions whose test path or device contains this (never if empty)")
+		latency         = flag.Duration("latency", 0, "Delay before every response")
+		rateLimit       = flag.Int("rate-limit", 0, "Requests allowed per second before answering 429 (0 for no limit)")
+	)
+	flag.Parse()
+
+	fake := bstest.New(bstest.Options{
+		Username:        *username,
+		AccessKey:       *accessKey,
+		QueueDelay:      *queueDelay,
+		SessionDuration: *sessionDuration,
+		Latency:         *latency,
+		RateLimit:       *rateLimit,
+		Result: func(app, device, test string) string {
+			if *failMatch != "" && (strings.Contains(test, *failMatch) || strings.Contains(device, *failMatch)) {
+				return fmt.Sprintf("test %s failed on %s", test, device)
+			}
+			return ""
+		},
+	})
+
+	slog.Info("Serving fake BrowserStack API", "addr", *addr)
+	if err := http.ListenAndServe(*addr, fake); err != nil {
+		slog.Error("Fake BrowserStack server failed", "error", err)
+		os.Exit(1)
+	}
+}
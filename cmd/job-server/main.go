@@ -2,78 +2,216 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 
 	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/dashboard"
+	"qualgent-test-platform/internal/health"
+	"qualgent-test-platform/internal/ingest"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/metrics"
 	"qualgent-test-platform/internal/scheduler"
 	"qualgent-test-platform/internal/server"
 	"qualgent-test-platform/internal/store"
+	"qualgent-test-platform/internal/tracing"
 )
 
 func main() {
-	// Get configuration from environment variables
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "user")
-	dbPassword := getEnv("DB_PASSWORD", "password")
-	dbName := getEnv("DB_NAME", "qg_jobs")
-	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
-	grpcPort := getEnv("GRPC_PORT", "8080")
-	// Create database connection string
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
+	var (
+		configPath  = flag.String("config", os.Getenv("QG_CONFIG"), "Path to a YAML config file (env QG_CONFIG)")
+		printConfig = flag.Bool("print-config", false, "Print the effective configuration and exit")
+		devMode     = flag.Bool("dev", false, "Keep all state in memory instead of Postgres and Redis")
+	)
+	flag.Parse()
+
+	// Load configuration: defaults, then the config file, then environment
+	cfg, err := config.LoadServer(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if *printConfig {
+		if err := config.Print(os.Stdout, "server", cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Initialize stores
-	postgresStore, err := store.NewPostgresStore(connStr)
+	// Subcommands run instead of the server
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			fmt.Fprintf(os.Stderr, "Unknown command %q\n", args[0])
+			os.Exit(2)
+		}
+		if err := runMigrate(cfg, args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logging.Init("job-server", logging.Options{
+		Level:            cfg.Log.Level,
+		Format:           cfg.Log.Format,
+		SampleInitial:    cfg.Log.SampleInitial,
+		SampleThereafter: cfg.Log.SampleThereafter,
+	})
+	grpcPort := cfg.GRPCPort
+	httpPort := cfg.HTTPPort
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), "job-server")
 	if err != nil {
-		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		logging.Fatal("Failed to initialize tracing", "error", err)
 	}
-	defer postgresStore.Close()
+	defer shutdownTracing(context.Background())
 
-	redisStore, err := store.NewRedisStore(redisAddr)
-			if err != nil {
-		log.Printf("Failed to connect to Redis: %v", err)
-		os.Exit(1)
+	// Initialize stores
+	var (
+		jobStore   store.JobStore
+		agentStore store.AgentStore
+		queueStore store.QueueStore
+		cacheStore store.CacheStore
+	)
+	if *devMode {
+		slog.Warn("Running in dev mode: all state is in memory and is lost on exit")
+		memoryStore := store.NewMemoryStore()
+		memoryCache := store.NewMemoryCache()
+		jobStore, agentStore = memoryStore, memoryStore
+		queueStore, cacheStore = memoryCache, memoryCache
+	} else {
+		db, err := openSQLStore(cfg.Database)
+		if err != nil {
+			logging.Fatal("Failed to open job store", "driver", cfg.Database.Driver, "error", err)
+		}
+		defer db.Close()
+
+		// Refuse to run against a schema this binary wasn't built for
+		if err := db.CheckSchema(context.Background()); err != nil {
+			logging.Fatal("Database schema is not compatible, run \"job-server migrate up\"", "error", err)
+		}
+
+		// Redis is optional: without it the server runs degraded on the
+		// database until Redis comes back
+		cache := store.NewFailoverCache(cfg.Redis.Addr, db, cfg.Redis.CheckInterval)
+		cache.Start(context.Background())
+		defer cache.Close()
+
+		jobStore, agentStore = db, db
+		queueStore, cacheStore = cache, cache
 	}
-	defer redisStore.Close()
 
 	// Initialize scheduler
 	instanceID := uuid.New().String()
-	sched := scheduler.NewScheduler(postgresStore, redisStore, instanceID)
+	sched := scheduler.NewScheduler(jobStore, queueStore, cacheStore, instanceID, cfg.Scheduler)
+
+	// Initialize ingestion, which validates jobs before the scheduler sees them
+	ingester := ingest.NewWorker(jobStore, queueStore, cacheStore, ingest.DefaultSteps(cfg.Ingest), cfg.Ingest, cfg.Cache)
 
 	// Initialize gRPC service
-	jobService := server.NewJobService(postgresStore, redisStore)
+	sessions := server.NewSessions()
+	jobService := server.NewJobService(jobStore, agentStore, queueStore, cacheStore, sessions, cfg.Cache)
 
 	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
 	pb.RegisterJobServiceServer(grpcServer, jobService)
 
-	// Start scheduler
+	// Operator actions and reflection have no auth, so they get their own
+	// listener, on localhost unless configured otherwise
+	adminServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	pb.RegisterAdminServiceServer(adminServer, server.NewAdminService(jobStore, agentStore, queueStore, cacheStore, sched, sessions, cfg.Cache))
+	reflection.Register(adminServer)
+
+	// Report health from job store and Redis reachability; losing Redis
+	// only degrades the server
+	checker := health.NewChecker(cfg.Health.CheckInterval, pb.JobService_ServiceDesc.ServiceName)
+	if *devMode {
+		checker.AddCheck("memory-store", jobStore.Ping)
+		checker.AddCheck("memory-cache", cacheStore.Ping)
+	} else {
+		checker.AddCheck(cfg.Database.Driver, jobStore.Ping)
+		checker.AddOptionalCheck("redis", cacheStore.Ping)
+	}
+	healthpb.RegisterHealthServer(grpcServer, checker.Server())
+
+	// Initialize web dashboard
+	dash, err := dashboard.NewDashboard(jobStore, agentStore, cfg.Dashboard.RefreshInterval)
+	if err != nil {
+		logging.Fatal("Failed to initialize dashboard", "error", err)
+	}
+
+	// Expose Prometheus metrics alongside the dashboard
+	prometheus.MustRegister(metrics.NewQueueCollector(queueStore, []string{"emulator", "device", "browserstack", "web"}))
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", checker.LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
+	mux.Handle("/", dash)
+
+	httpServer := &http.Server{
+		Addr:     ":" + httpPort,
+		Handler:  mux,
+		ErrorLog: logging.StdLogger(slog.LevelWarn),
+	}
+
+	// Start ingestion and scheduler
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	checker.Start(ctx)
+	ingester.Start(ctx)
 	sched.Start(ctx)
 
 	// Start gRPC server
 	lis, err := net.Listen("tcp", ":"+grpcPort)
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		logging.Fatal("Failed to listen", "port", grpcPort, "error", err)
 	}
 
-	log.Printf("Job server listening on port %s", grpcPort)
-	log.Printf("Scheduler instance ID: %s", instanceID)
+	slog.Info("Job server listening", "port", grpcPort, "instance_id", instanceID)
 
 	// Start server in a goroutine
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("Failed to serve: %v", err)
+			logging.Fatal("Failed to serve", "error", err)
+		}
+	}()
+
+	adminLis, err := net.Listen("tcp", cfg.AdminAddr)
+	if err != nil {
+		logging.Fatal("Failed to listen", "addr", cfg.AdminAddr, "error", err)
+	}
+
+	slog.Info("Admin service listening", "addr", cfg.AdminAddr)
+
+	go func() {
+		if err := adminServer.Serve(adminLis); err != nil {
+			logging.Fatal("Failed to serve admin service", "error", err)
+		}
+	}()
+
+	// Start dashboard in a goroutine
+	go func() {
+		slog.Info("Dashboard and metrics listening", "port", httpPort)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Fatal("Failed to serve dashboard", "error", err)
 		}
 	}()
 
@@ -82,18 +220,23 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
-	// Graceful shutdown
+	// Graceful shutdown: report NOT_SERVING first so traffic drains away
+	// while the scheduler and in-flight RPCs finish
+	checker.Drain()
+	ingester.Stop()
 	sched.Stop()
+	// End agent sessions, or GracefulStop would wait on them forever
+	sessions.CloseAll()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
 	grpcServer.GracefulStop()
+	adminServer.GracefulStop()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to shut down dashboard", "error", err)
+	}
 
-	log.Println("Server stopped")
+	slog.Info("Server stopped")
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
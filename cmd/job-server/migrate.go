@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/store"
+)
+
+const migrateUsage = "usage: job-server migrate up|down [steps]|status"
+
+// runMigrate implements "job-server migrate up|down [steps]|status".
+func runMigrate(cfg config.Server, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// Validate arguments before touching the database
+	steps := 1
+	switch args[0] {
+	case "up", "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	case "down":
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("steps must be a positive integer, got %q", args[1])
+			}
+			steps = n
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q; %s", args[0], migrateUsage)
+	}
+
+	db, err := openSQLStore(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return nil
+
+	case "down":
+		reverted, err := db.MigrateDown(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("Reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Nothing to revert")
+		}
+		return nil
+
+	default:
+		current, err := db.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		statuses, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Schema version: %d (binary expects %d)\n\n", current, db.LatestSchemaVersion())
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+}
+
+// sqlStore is a job and agent store backed by a migrated SQL database.
+type sqlStore interface {
+	store.JobStore
+	store.AgentStore
+	store.Migrator
+	store.Locker
+	Close() error
+}
+
+// openSQLStore connects to the database selected by cfg.Driver.
+func openSQLStore(cfg config.Database) (sqlStore, error) {
+	if cfg.Driver == "sqlite" {
+		sqliteStore, err := store.NewSQLiteStore(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open SQLite database %s: %w", cfg.Path, err)
+		}
+		return sqliteStore, nil
+	}
+
+	postgresStore, err := store.NewPostgresStore(cfg.ConnString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+	return postgresStore, nil
+}
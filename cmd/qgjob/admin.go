@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "qualgent-test-platform/api/proto"
+)
+
+var (
+	adminServerAddr string
+	adminAgentID    string
+	failReason      string
+)
+
+func newAdminCmd() *cobra.Command {
+	adminCmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Operator commands for jobs, agents and the scheduler",
+		Long:  `Operator commands backed by the job server's AdminService, served on its admin address.`,
+	}
+
+	requeueCmd := &cobra.Command{
+		Use:   "requeue",
+		Short: "Put a job back to PENDING",
+		RunE:  requeueJob,
+	}
+	requeueCmd.Flags().StringVar(&jobID, "job-id", "", "Job ID (required)")
+	requeueCmd.MarkFlagRequired("job-id")
+
+	failCmd := &cobra.Command{
+		Use:   "fail",
+		Short: "Force a job to FAILED",
+		RunE:  failJob,
+	}
+	failCmd.Flags().StringVar(&jobID, "job-id", "", "Job ID (required)")
+	failCmd.Flags().StringVar(&failReason, "reason", "", "Reason recorded as the job's error message")
+	failCmd.MarkFlagRequired("job-id")
+
+	drainCmd := &cobra.Command{
+		Use:   "drain-agent",
+		Short: "Stop an agent from receiving new jobs",
+		RunE:  drainAgent,
+	}
+	drainCmd.Flags().StringVar(&adminAgentID, "agent-id", "", "Agent ID (required)")
+	drainCmd.MarkFlagRequired("agent-id")
+
+	evictCmd := &cobra.Command{
+		Use:   "evict-agent",
+		Short: "Take an agent offline and requeue its jobs",
+		RunE:  evictAgent,
+	}
+	evictCmd.Flags().StringVar(&adminAgentID, "agent-id", "", "Agent ID (required)")
+	evictCmd.MarkFlagRequired("agent-id")
+
+	schedulerCmd := &cobra.Command{
+		Use:   "scheduler",
+		Short: "Inspect or control the scheduler",
+	}
+	schedulerCmd.AddCommand(
+		&cobra.Command{Use: "pause", Short: "Pause job grouping on all instances", RunE: pauseScheduler},
+		&cobra.Command{Use: "resume", Short: "Resume job grouping", RunE: resumeScheduler},
+		&cobra.Command{Use: "state", Short: "Dump scheduler state", RunE: schedulerState},
+	)
+
+	adminCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	adminCmd.AddCommand(requeueCmd, failCmd, drainCmd, evictCmd, schedulerCmd)
+	return adminCmd
+}
+
+func withAdminClient(fn func(ctx context.Context, client pb.AdminServiceClient) error) error {
+	conn, err := grpc.Dial(adminServerAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return fn(ctx, pb.NewAdminServiceClient(conn))
+}
+
+func requeueJob(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.RequeueJob(ctx, &pb.RequeueJobRequest{JobId: jobID})
+		if err != nil {
+			return fmt.Errorf("failed to requeue job: %w", err)
+		}
+		return printAdminResult(resp, fmt.Sprintf("Job %s requeued (status %s)", resp.JobId, resp.Status))
+	})
+}
+
+func failJob(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.FailJob(ctx, &pb.FailJobRequest{JobId: jobID, Reason: failReason})
+		if err != nil {
+			return fmt.Errorf("failed to fail job: %w", err)
+		}
+		return printAdminResult(resp, fmt.Sprintf("Job %s marked %s", resp.JobId, resp.Status))
+	})
+}
+
+func drainAgent(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.DrainAgent(ctx, &pb.AdminAgentRequest{AgentId: adminAgentID})
+		if err != nil {
+			return fmt.Errorf("failed to drain agent: %w", err)
+		}
+		return printAdminResult(resp, fmt.Sprintf("Agent %s is %s", resp.AgentId, resp.Status))
+	})
+}
+
+func evictAgent(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.EvictAgent(ctx, &pb.AdminAgentRequest{AgentId: adminAgentID})
+		if err != nil {
+			return fmt.Errorf("failed to evict agent: %w", err)
+		}
+		return printAdminResult(resp, fmt.Sprintf("Agent %s is %s, %d job(s) requeued", resp.AgentId, resp.Status, resp.RequeuedJobs))
+	})
+}
+
+func pauseScheduler(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.PauseScheduler(ctx, &pb.PauseSchedulerRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to pause scheduler: %w", err)
+		}
+		return printSchedulerState(resp)
+	})
+}
+
+func resumeScheduler(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.ResumeScheduler(ctx, &pb.ResumeSchedulerRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to resume scheduler: %w", err)
+		}
+		return printSchedulerState(resp)
+	})
+}
+
+func schedulerState(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.GetSchedulerState(ctx, &pb.GetSchedulerStateRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to get scheduler state: %w", err)
+		}
+		return printSchedulerState(resp)
+	})
+}
+
+func printAdminResult(resp interface{}, text string) error {
+	if jsonOutput {
+		jsonBytes, _ := json.Marshal(resp)
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+	fmt.Println(text)
+	return nil
+}
+
+func printSchedulerState(resp *pb.SchedulerStateResponse) error {
+	if jsonOutput {
+		jsonBytes, _ := json.Marshal(resp)
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	fmt.Printf("Scheduler State:\n")
+	fmt.Printf("Instance ID: %s\n", resp.InstanceId)
+	fmt.Printf("Paused: %t\n", resp.Paused)
+	if resp.LastCycleAt != nil {
+		fmt.Printf("Last cycle: %s (%d ms, %d jobs, %d groups)\n",
+			resp.LastCycleAt.AsTime().Format(time.RFC3339), resp.LastCycleDurationMs, resp.LastCycleJobs, resp.LastCycleGroups)
+	} else {
+		fmt.Printf("Last cycle: never\n")
+	}
+	if resp.LastError != "" {
+		fmt.Printf("Last error: %s\n", resp.LastError)
+	}
+	fmt.Printf("Ingestion queue: %d\n", resp.IngestionQueueLength)
+
+	statuses := make([]string, 0, len(resp.JobCounts))
+	for s := range resp.JobCounts {
+		statuses = append(statuses, s)
+	}
+	sort.Strings(statuses)
+	for _, s := range statuses {
+		fmt.Printf("Jobs %s: %d\n", s, resp.JobCounts[s])
+	}
+	return nil
+}
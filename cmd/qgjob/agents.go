@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "qualgent-test-platform/api/proto"
+)
+
+var includeOffline bool
+
+func newAgentsCmd() *cobra.Command {
+	agentsCmd := &cobra.Command{
+		Use:   "agents",
+		Short: "List, drain and deregister agents",
+		Long:  `Inspect the agents registered with the job server and take them out of service.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List registered agents",
+		RunE:  listAgents,
+	}
+	listCmd.Flags().BoolVar(&includeOffline, "all", false, "Include OFFLINE agents")
+
+	drainCmd := &cobra.Command{
+		Use:   "drain",
+		Short: "Stop an agent from receiving new jobs",
+		RunE:  drainAgent,
+	}
+	drainCmd.Flags().StringVar(&adminAgentID, "agent-id", "", "Agent ID (required)")
+	drainCmd.MarkFlagRequired("agent-id")
+
+	deregisterCmd := &cobra.Command{
+		Use:   "deregister",
+		Short: "Take an agent offline and hand back its jobs",
+		RunE:  deregisterAgent,
+	}
+	deregisterCmd.Flags().StringVar(&adminAgentID, "agent-id", "", "Agent ID (required)")
+	deregisterCmd.MarkFlagRequired("agent-id")
+
+	agentsCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	agentsCmd.AddCommand(listCmd, drainCmd, deregisterCmd)
+	return agentsCmd
+}
+
+func listAgents(cmd *cobra.Command, args []string) error {
+	return withAdminClient(func(ctx context.Context, client pb.AdminServiceClient) error {
+		resp, err := client.ListAgents(ctx, &pb.ListAgentsRequest{IncludeOffline: includeOffline})
+		if err != nil {
+			return fmt.Errorf("failed to list agents: %w", err)
+		}
+
+		if jsonOutput {
+			jsonBytes, _ := json.Marshal(resp)
+			fmt.Println(string(jsonBytes))
+			return nil
+		}
+
+		if len(resp.Agents) == 0 {
+			fmt.Println("No agents registered.")
+			return nil
+		}
+		fmt.Printf("%-36s  %-20s  %-20s  %-8s  %-5s  %-20s  %s\n", "AGENT ID", "HOSTNAME", "TARGETS", "STATUS", "ALIVE", "REGISTERED", "LABELS")
+		for _, agent := range resp.Agents {
+			alive := "no"
+			if agent.Alive {
+				alive = "yes"
+			}
+			fmt.Printf("%-36s  %-20s  %-20s  %-8s  %-5s  %-20s  %s\n",
+				agent.AgentId, agent.Hostname, agent.TargetCapability, agent.Status, alive,
+				agent.RegisteredAt.AsTime().Format(time.RFC3339), formatLabels(agent.Labels))
+		}
+		return nil
+	})
+}
+
+func deregisterAgent(cmd *cobra.Command, args []string) error {
+	conn, err := grpc.Dial(serverAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := pb.NewJobServiceClient(conn).DeregisterAgent(ctx, &pb.DeregisterAgentRequest{AgentId: adminAgentID})
+	if err != nil {
+		return fmt.Errorf("failed to deregister agent: %w", err)
+	}
+	return printAdminResult(resp, fmt.Sprintf("Agent %s is %s, %d job(s) requeued", resp.AgentId, resp.Status, resp.RequeuedJobs))
+}
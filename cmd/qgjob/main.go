@@ -5,14 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
 	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/tracing"
 )
 var (
 	serverAddr string
@@ -22,9 +28,13 @@ var (
 	priority   int32
 	target     string
 	jobID      string
+	groupID    string
 	jsonOutput bool
 	webAppURL  string
 	testType   string
+	requiredLabels  map[string]string
+	preferredLabels map[string]string
+	devices         []string
 )
 
 func main() {
@@ -36,6 +46,7 @@ func main() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&serverAddr, "server", "localhost:8080", "RPC server address")
+	rootCmd.PersistentFlags().StringVar(&adminServerAddr, "admin-server", "localhost:8082", "Admin RPC server address")
 
 	// Submit command
 	submitCmd := &cobra.Command{
@@ -51,6 +62,9 @@ func main() {
 	submitCmd.Flags().StringVar(&target, "target", "emulator", "Execution target (emulator|device|browserstack|web)")
 	submitCmd.Flags().StringVar(&webAppURL, "web-app-url", "", "URL of the web application (required for web target)")
 	submitCmd.Flags().StringVar(&testType, "test-type", "", "Type of test (PLAYWRIGHT|ESPRESSO)")
+	submitCmd.Flags().StringToStringVar(&requiredLabels, "require", nil, "Agent labels the job requires, e.g. os_version=13,device=pixel7")
+	submitCmd.Flags().StringToStringVar(&preferredLabels, "prefer", nil, "Agent labels the job prefers, e.g. region=us-east")
+	submitCmd.Flags().StringSliceVar(&devices, "devices", nil, `Devices to run the test on as name:os_version, one job each, e.g. "Pixel 7:13,Galaxy S22:12"`)
 	submitCmd.MarkFlagRequired("org-id")
 	submitCmd.MarkFlagRequired("test")
 
@@ -70,9 +84,35 @@ func main() {
 	statusCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	statusCmd.MarkFlagRequired("job-id")
 
-	rootCmd.AddCommand(submitCmd, statusCmd)
+	// Group command
+	groupCmd := &cobra.Command{
+		Use:   "group",
+		Short: "Get the status of a job group",
+		Long:  `Get the rolled-up status of a job group and the status of each job in it.`,
+		RunE:  getJobGroup,
+	}
+	groupCmd.Flags().StringVar(&groupID, "group-id", "", "Job group ID (required)")
+	groupCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	groupCmd.MarkFlagRequired("group-id")
+
+	rootCmd.AddCommand(submitCmd, statusCmd, groupCmd, newAgentsCmd(), newAdminCmd())
 
-	if err := rootCmd.Execute(); err != nil {
+	// Diagnostics go to stderr; command output stays on stdout
+	logOpts := logging.OptionsFromEnv()
+	if logOpts.Level == "" {
+		logOpts.Level = "warn"
+	}
+	logging.Init("qgjob", logOpts)
+
+	shutdownTracing, err := tracing.Init(context.Background(), "qgjob")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tracing disabled: %v\n", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	err = rootCmd.Execute()
+	shutdownTracing(context.Background())
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -103,7 +143,10 @@ func submitJob(cmd *cobra.Command, args []string) error {
 	}
 
 	// Connect to gRPC server
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(serverAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to connect to server: %w", err)
 	}
@@ -113,30 +156,46 @@ func submitJob(cmd *cobra.Command, args []string) error {
 
 	// Create request
 	req := &pb.SubmitJobRequest{
-		OrgId:          orgID,
-		AppVersionId:   appVersionID,
-		TestPath:       testPath,
-		Priority:       priority,
-		Target:         parseTarget(target),
-		IdempotencyKey: uuid.New().String(),
-		WebAppUrl:      webAppURL,
-		TestType:       parseTestType(testType),
+		OrgId:           orgID,
+		AppVersionId:    appVersionID,
+		TestPath:        testPath,
+		Priority:        priority,
+		Target:          parseTarget(target),
+		IdempotencyKey:  uuid.New().String(),
+		WebAppUrl:       webAppURL,
+		TestType:        parseTestType(testType),
+		RequiredLabels:  requiredLabels,
+		PreferredLabels: preferredLabels,
+		Devices:         devices,
 	}
 
-	// Submit job
+	// Submit job under a root span so the whole lifecycle shares one trace
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	ctx, span := tracing.Tracer("qgjob").Start(ctx, "qgjob submit")
+	span.SetAttributes(
+		attribute.String("job.org_id", orgID),
+		attribute.String("job.target", target),
+		attribute.String("job.test_path", testPath),
+	)
+	defer span.End()
+
 	resp, err := client.SubmitJob(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to submit job: %w", err)
 	}
+	traceID := span.SpanContext().TraceID().String()
 
 	// Output result
 	if jsonOutput {
 		output := map[string]interface{}{
-			"job_id": resp.JobId,
-			"status": resp.Status.String(),
+			"job_id":   resp.JobId,
+			"status":   resp.Status.String(),
+			"trace_id": traceID,
+		}
+		if len(resp.DeviceJobs) > 0 {
+			output["device_jobs"] = deviceJobsJSON(resp.DeviceJobs)
 		}
 		jsonBytes, _ := json.Marshal(output)
 		fmt.Println(string(jsonBytes))
@@ -144,6 +203,10 @@ func submitJob(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Job submitted successfully!\n")
 		fmt.Printf("Job ID: %s\n", resp.JobId)
 		fmt.Printf("Status: %s\n", resp.Status.String())
+		if span.SpanContext().IsValid() {
+			fmt.Printf("Trace ID: %s\n", traceID)
+		}
+		printDeviceJobs(resp.DeviceJobs)
 	}
 
 	return nil
@@ -151,7 +214,10 @@ func submitJob(cmd *cobra.Command, args []string) error {
 
 func getJobStatus(cmd *cobra.Command, args []string) error {
 	// Connect to gRPC server
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(serverAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to connect to server: %w", err)
 	}
@@ -185,6 +251,13 @@ func getJobStatus(cmd *cobra.Command, args []string) error {
 			"video_url":  resp.VideoUrl,
 			"error_message": resp.ErrorMessage,
 			"test_duration": resp.TestDuration,
+			"trace_id":   resp.TraceId,
+			"job_group_id": resp.JobGroupId,
+			"device":     resp.Device,
+			"parent_job_id": resp.ParentJobId,
+		}
+		if len(resp.DeviceJobs) > 0 {
+			output["device_jobs"] = deviceJobsJSON(resp.DeviceJobs)
 		}
 		jsonBytes, _ := json.Marshal(output)
 		fmt.Println(string(jsonBytes))
@@ -211,6 +284,112 @@ func getJobStatus(cmd *cobra.Command, args []string) error {
 		if resp.TestDuration > 0 {
 			fmt.Printf("Duration: %d seconds\n", resp.TestDuration)
 		}
+		if resp.TraceId != "" {
+			fmt.Printf("Trace ID: %s\n", resp.TraceId)
+		}
+		if resp.JobGroupId != "" {
+			fmt.Printf("Group ID: %s\n", resp.JobGroupId)
+		}
+		if resp.Device != "" {
+			fmt.Printf("Device: %s\n", resp.Device)
+		}
+		if resp.ParentJobId != "" {
+			fmt.Printf("Matrix Job ID: %s\n", resp.ParentJobId)
+		}
+		printDeviceJobs(resp.DeviceJobs)
+	}
+
+	return nil
+}
+
+func getJobGroup(cmd *cobra.Command, args []string) error {
+	// Connect to gRPC server
+	conn, err := grpc.Dial(serverAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewJobServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := client.GetJobGroup(ctx, &pb.GetJobGroupRequest{GroupId: groupID})
+	if err != nil {
+		return fmt.Errorf("failed to get job group: %w", err)
+	}
+
+	completedAt := ""
+	if resp.CompletedAt != nil {
+		completedAt = resp.CompletedAt.AsTime().Format(time.RFC3339)
+	}
+
+	// Output result
+	if jsonOutput {
+		jobs := make([]map[string]interface{}, 0, len(resp.Jobs))
+		for _, job := range resp.Jobs {
+			jobs = append(jobs, map[string]interface{}{
+				"job_id":        job.JobId,
+				"status":        job.Status.String(),
+				"test_path":     job.TestPath,
+				"priority":      job.Priority,
+				"error_message": job.ErrorMessage,
+			})
+		}
+		output := map[string]interface{}{
+			"group_id":       resp.GroupId,
+			"status":         resp.Status,
+			"target":         resp.Target.String(),
+			"app_version_id": resp.AppVersionId,
+			"web_app_url":    resp.WebAppUrl,
+			"agent_id":       resp.AgentId,
+			"required_labels":  resp.RequiredLabels,
+			"preferred_labels": resp.PreferredLabels,
+			"device":         resp.Device,
+			"created_at":     resp.CreatedAt.AsTime().Format(time.RFC3339),
+			"completed_at":   completedAt,
+			"jobs":           jobs,
+		}
+		jsonBytes, _ := json.Marshal(output)
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	fmt.Printf("Job Group:\n")
+	fmt.Printf("Group ID: %s\n", resp.GroupId)
+	fmt.Printf("Status: %s\n", resp.Status)
+	fmt.Printf("Target: %s\n", resp.Target.String())
+	if resp.WebAppUrl != "" {
+		fmt.Printf("Web App URL: %s\n", resp.WebAppUrl)
+	} else {
+		fmt.Printf("App Version: %s\n", resp.AppVersionId)
+	}
+	if resp.AgentId != "" {
+		fmt.Printf("Agent ID: %s\n", resp.AgentId)
+	}
+	if len(resp.RequiredLabels) > 0 {
+		fmt.Printf("Required Labels: %s\n", formatLabels(resp.RequiredLabels))
+	}
+	if len(resp.PreferredLabels) > 0 {
+		fmt.Printf("Preferred Labels: %s\n", formatLabels(resp.PreferredLabels))
+	}
+	if resp.Device != "" {
+		fmt.Printf("Device: %s\n", resp.Device)
+	}
+	fmt.Printf("Created: %s\n", resp.CreatedAt.AsTime().Format(time.RFC3339))
+	if completedAt != "" {
+		fmt.Printf("Completed: %s\n", completedAt)
+	}
+	fmt.Printf("Jobs (%d):\n", len(resp.Jobs))
+	for _, job := range resp.Jobs {
+		fmt.Printf("  %s  %-9s  p%-2d  %s\n", job.JobId, job.Status.String(), job.Priority, job.TestPath)
+		if job.ErrorMessage != "" {
+			fmt.Printf("    Error: %s\n", job.ErrorMessage)
+		}
 	}
 
 	return nil
@@ -240,4 +419,57 @@ func parseTestType(testType string) pb.TestType {
 	default:
 		return pb.TestType_TEST_TYPE_UNSPECIFIED
 	}
-}
\ No newline at end of file
+}
+
+// formatLabels prints labels as sorted key=value pairs.
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// deviceJobsJSON lists a device matrix's jobs for --json output.
+func deviceJobsJSON(deviceJobs []*pb.DeviceJob) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(deviceJobs))
+	for _, job := range deviceJobs {
+		out = append(out, map[string]interface{}{
+			"device":        job.Device,
+			"job_id":        job.JobId,
+			"status":        job.Status.String(),
+			"error_message": job.ErrorMessage,
+			"session_id":    job.SessionId,
+			"logs_url":      job.LogsUrl,
+			"video_url":     job.VideoUrl,
+			"test_duration": job.TestDuration,
+		})
+	}
+	return out
+}
+
+// printDeviceJobs prints a device matrix's result on each device, so a test
+// that passes on one device and fails on another shows which.
+func printDeviceJobs(deviceJobs []*pb.DeviceJob) {
+	if len(deviceJobs) == 0 {
+		return
+	}
+	width, passed := 0, 0
+	for _, job := range deviceJobs {
+		width = max(width, len(job.Device))
+		if job.Status == pb.Status_COMPLETED {
+			passed++
+		}
+	}
+	fmt.Printf("Devices (%d passed of %d):\n", passed, len(deviceJobs))
+	for _, job := range deviceJobs {
+		fmt.Printf("  %-*s  %-9s  %s\n", width, job.Device, job.Status.String(), job.JobId)
+		if job.ErrorMessage != "" {
+			fmt.Printf("    Error: %s\n", job.ErrorMessage)
+		}
+		if job.LogsUrl != "" {
+			fmt.Printf("    Logs: %s\n", job.LogsUrl)
+		}
+	}
+}
@@ -1,36 +1,45 @@
 package agent
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
-	"net/http"
-	"os"
+	"io"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 
 	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/metrics"
+	"qualgent-test-platform/internal/tracing"
 )
 
-type AppWrightAgent struct {
-	client       pb.JobServiceClient
-	agentID      string
-	hostname     string
-	browserStack *BrowserStackClient
-}
+// cancelTimeout bounds how long an executor may take to cancel a run.
+const cancelTimeout = 30 * time.Second
 
-type BrowserStackClient struct {
-	username string
-	accessKey string
-	baseURL   string
-	httpClient *http.Client
+// AppWrightAgent runs the job groups the server assigns it, handing each
+// to the executor registered for the group's target.
+type AppWrightAgent struct {
+	client            pb.JobServiceClient
+	agentID           string
+	hostname          string
+	labels            map[string]string
+	reconnectInterval time.Duration
+	heartbeatInterval time.Duration
+	slots             int
+	shutdownTimeout   time.Duration
+	executors         Executors
 }
 
 type AppWrightTestConfig struct {
@@ -45,34 +54,35 @@ type TestResult struct {
 	Status    string `json:"status"`
 	LogsURL   string `json:"logs_url"`
 	VideoURL  string `json:"video_url"`
+	Error     string `json:"error,omitempty"`
 }
 
-func NewAppWrightAgent(serverAddr, hostname string) (*AppWrightAgent, error) {
+func NewAppWrightAgent(cfg config.Agent, executors Executors) (*AppWrightAgent, error) {
+	if len(executors) == 0 {
+		return nil, fmt.Errorf("at least one executor is required")
+	}
+
 	// Connect to gRPC server
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(cfg.Server,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to server: %w", err)
 	}
 
 	client := pb.NewJobServiceClient(conn)
 
-	// Initialize BrowserStack client
-	browserStack := &BrowserStackClient{
-		username:   os.Getenv("BROWSERSTACK_USERNAME"),
-		accessKey:  os.Getenv("BROWSERSTACK_ACCESS_KEY"),
-		baseURL:    "https://api-cloud.browserstack.com/app-automate/v2",
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-	}
-
-	if browserStack.username == "" || browserStack.accessKey == "" {
-		return nil, fmt.Errorf("BROWSERSTACK_USERNAME and BROWSERSTACK_ACCESS_KEY environment variables are required")
-	}
-
 	agent := &AppWrightAgent{
-		client:       client,
-		agentID:      uuid.New().String(),
-		hostname:     hostname,
-		browserStack: browserStack,
+		client:            client,
+		agentID:           uuid.New().String(),
+		hostname:          registeredName(cfg),
+		labels:            cfg.Labels,
+		reconnectInterval: cfg.ReconnectInterval,
+		heartbeatInterval: cfg.HeartbeatInterval,
+		slots:             cfg.Slots,
+		shutdownTimeout:   cfg.ShutdownTimeout,
+		executors:         executors,
 	}
 
 	// Register agent with server
@@ -83,13 +93,23 @@ func NewAppWrightAgent(serverAddr, hostname string) (*AppWrightAgent, error) {
 	return agent, nil
 }
 
+// registeredName is the name the agent registers as: its hostname, followed
+// by its name if it has one.
+func registeredName(cfg config.Agent) string {
+	if cfg.Name == "" {
+		return cfg.Hostname
+	}
+	return cfg.Hostname + "/" + cfg.Name
+}
+
 func (a *AppWrightAgent) register() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	req := &pb.RegisterAgentRequest{
 		Hostname:         a.hostname,
-		TargetCapability: "browserstack",
+		TargetCapability: a.executors.capability(),
+		Labels:           a.labels,
 	}
 
 	resp, err := a.client.RegisterAgent(ctx, req)
@@ -97,191 +117,475 @@ func (a *AppWrightAgent) register() error {
 		return fmt.Errorf("failed to register agent: %w", err)
 	}
 
-	log.Printf("Registered agent with ID: %s", resp.AgentId)
+	// Use the server-assigned ID so logs and status updates line up
+	a.agentID = resp.AgentId
+	slog.Info("Registered agent", logging.KeyAgentID, a.agentID, "hostname", a.hostname, "targets", req.TargetCapability, "labels", a.labels)
 	return nil
 }
 
-func (a *AppWrightAgent) Start(ctx context.Context) error {
-	log.Printf("AppWright Agent started on %s", a.hostname)
+// deregister takes the agent offline on the server, which requeues any jobs
+// it stopped without finishing. Otherwise the agent would be left looking
+// available after it has gone.
+func (a *AppWrightAgent) deregister() {
+	ctx, cancel := context.WithTimeout(logging.WithAgent(context.Background(), a.agentID), 10*time.Second)
+	defer cancel()
 
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+	resp, err := a.client.DeregisterAgent(ctx, &pb.DeregisterAgentRequest{AgentId: a.agentID})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to deregister agent", "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "Deregistered agent", "requeued_jobs", resp.RequeuedJobs)
+}
+
+// Start keeps a session open with the server until ctx is done, opening a
+// new one after reconnectInterval whenever it drops. Once ctx is done it
+// waits up to shutdownTimeout for running job groups, then deregisters
+// before returning.
+func (a *AppWrightAgent) Start(ctx context.Context) error {
+	slog.InfoContext(logging.WithAgent(ctx, a.agentID), "AppWright Agent started", "hostname", a.hostname, "slots", a.slots)
+	metrics.AgentSlots.Set(float64(a.slots))
+	defer a.deregister()
 
 	for {
-		select {
-		case <-ctx.Done():
+		err := a.runSession(logging.WithAgent(ctx, a.agentID))
+		if ctx.Err() != nil {
 			return nil
-		case <-ticker.C:
-			if err := a.processJobs(ctx); err != nil {
-				log.Printf("Failed to process jobs: %v", err)
-			}
 		}
-	}
-}
 
-func (a *AppWrightAgent) processJobs(ctx context.Context) error {
-	// Fetch a job from the server
-	req := &pb.FetchJobRequest{
-		TargetCapability: "browserstack",
-	}
-	job, err := a.client.FetchJob(ctx, req)
-	if err != nil {
+		// The server has no record of this agent, e.g. after a dev-mode
+		// restart, so register again before reconnecting
 		if status.Code(err) == codes.NotFound {
-			log.Println("No jobs available for target: browserstack")
+			if err := a.register(); err != nil {
+				slog.ErrorContext(ctx, "Failed to re-register agent", "error", err)
+			}
+		}
+
+		slog.WarnContext(logging.WithAgent(ctx, a.agentID), "Agent session ended, reconnecting", "error", err, "retry_in", a.reconnectInterval)
+		select {
+		case <-ctx.Done():
 			return nil
+		case <-time.After(a.reconnectInterval):
 		}
-		return fmt.Errorf("failed to fetch job: %w", err)
 	}
+}
+
+// session is one open AgentSession stream and the job groups running over
+// it. jobs maps each job not yet reported or cancelled to its group's run.
+// Assigned groups wait on queue for one of the session's slots, and active
+// counts the groups assigned that haven't finished.
+type session struct {
+	stream pb.JobService_AgentSessionClient
+	sendMu sync.Mutex
+	queue  chan *groupRun
+
+	mu     sync.Mutex
+	jobs   map[string]*groupRun
+	active int
+	// Signalled when a group finishes
+	idle chan struct{}
+}
+
+// groupRun is a job group assigned over a session. It is stopped once none
+// of its jobs are left to report.
+type groupRun struct {
+	group   *pb.GroupAssignment
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pending int
+}
 
-	log.Printf("Processing job %s", job.JobId)
+func (s *session) send(msg *pb.AgentMessage) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.stream.Send(msg)
+}
+
+func (s *session) closeSend() error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.stream.CloseSend()
+}
+
+func (s *session) progress(jobID string, st pb.Status) error {
+	return s.report(&pb.JobProgress{JobId: jobID, Status: st})
+}
+
+func (s *session) report(progress *pb.JobProgress) error {
+	return s.send(&pb.AgentMessage{Message: &pb.AgentMessage_Progress{Progress: progress}})
+}
 
-	// Update job status to RUNNING
-	updateReq := &pb.UpdateJobStatusRequest{
-		JobId:  job.JobId,
-		Status: pb.Status_RUNNING,
-		AgentId: a.agentID,
+// release stops tracking a job, reporting whether it was still tracked.
+// Its group is stopped when this was the group's last job.
+func (s *session) release(jobID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, ok := s.jobs[jobID]
+	if !ok {
+		return false
 	}
-	if _, err := a.client.UpdateJobStatus(ctx, updateReq); err != nil {
-		log.Printf("Failed to update job %s to RUNNING: %v", job.JobId, err)
-		return nil // Don't proceed with a job we can't update
+	delete(s.jobs, jobID)
+	run.pending--
+	if run.pending == 0 {
+		run.cancel()
 	}
+	return true
+}
 
-	// Execute the test
-	result, err := a.executeAppWrightTest(ctx, &pb.SubmitJobRequest{
-		AppVersionId: job.AppVersionId,
-		TestPath:     job.TestPath,
-	})
+// runSession opens a session, runs the job groups the server assigns over
+// it on a pool of slots and returns when the stream ends, or once ctx is
+// done and the session has drained. Groups still running when the stream
+// ends are stopped: the server requeues their jobs as soon as it sees the
+// session go.
+func (a *AppWrightAgent) runSession(ctx context.Context) error {
+	// The stream outlives ctx so that running groups can still report
+	// while the session drains
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
 
-	// Update job status based on the result
-	finalStatus := pb.Status_COMPLETED
+	stream, err := a.client.AgentSession(streamCtx)
 	if err != nil {
-		log.Printf("Test failed for job %s: %v", job.JobId, err)
-		finalStatus = pb.Status_FAILED
-	} else {
-		log.Printf("Test completed for job %s with status: %s", job.JobId, result.Status)
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	sess := &session{
+		stream: stream,
+		queue:  make(chan *groupRun, a.slots),
+		jobs:   make(map[string]*groupRun),
+		idle:   make(chan struct{}, 1),
 	}
 
-	updateReq.Status = finalStatus
-	if _, err := a.client.UpdateJobStatus(ctx, updateReq); err != nil {
-		log.Printf("Failed to update final status for job %s: %v", job.JobId, err)
+	hello := &pb.AgentHello{AgentId: a.agentID, Slots: int32(a.slots)}
+	if err := sess.send(&pb.AgentMessage{Message: &pb.AgentMessage_Hello{Hello: hello}}); err != nil {
+		return fmt.Errorf("failed to send hello: %w", err)
+	}
+	slog.InfoContext(ctx, "Agent session opened", "slots", hello.Slots)
+
+	var wg sync.WaitGroup
+	wg.Add(1 + a.slots)
+	go func() {
+		defer wg.Done()
+		a.sendHeartbeats(streamCtx, sess)
+	}()
+	for i := 0; i < a.slots; i++ {
+		go func() {
+			defer wg.Done()
+			a.runSlot(streamCtx, sess)
+		}()
 	}
 
-	return nil
+	// Receive on a separate goroutine so that shutdown can drain the
+	// session while the server keeps talking to it
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			a.handleServerMessage(streamCtx, sess, msg)
+		}
+	}()
+
+	select {
+	case err = <-recvErr:
+	case <-ctx.Done():
+		err = a.drain(ctx, sess, recvErr)
+	}
+	cancel()
+	wg.Wait()
+	return err
 }
 
-func (a *AppWrightAgent) updateHeartbeat(ctx context.Context) error {
-	// For heartbeat, we don't need to update job status
-	// Just keep the agent alive in the system
-	return nil
+func (a *AppWrightAgent) handleServerMessage(ctx context.Context, sess *session, msg *pb.ServerMessage) {
+	switch m := msg.Message.(type) {
+	case *pb.ServerMessage_GroupAssignment:
+		a.startGroup(ctx, sess, m.GroupAssignment)
+
+	case *pb.ServerMessage_Assignment:
+		// Older servers hand out one job at a time
+		job := m.Assignment
+		a.startGroup(ctx, sess, &pb.GroupAssignment{
+			AppVersionId: job.AppVersionId,
+			Target:       job.Target,
+			WebAppUrl:    job.WebAppUrl,
+			TestType:     job.TestType,
+			Device:       job.Device,
+			Jobs:         []*pb.FetchJobResponse{job},
+		})
+
+	case *pb.ServerMessage_Cancellation:
+		if sess.release(m.Cancellation.JobId) {
+			slog.InfoContext(logging.WithJob(ctx, m.Cancellation.JobId, ""), "Job cancelled by server", "reason", m.Cancellation.Reason)
+		}
+	}
 }
 
-func (a *AppWrightAgent) executeAppWrightTest(ctx context.Context, job *pb.SubmitJobRequest) (*TestResult, error) {
-	// Create AppWright test configuration with minimal payload
-	payload := map[string]interface{}{
-		"app": job.AppVersionId, // This should be the app URL or app ID
-		"devices": []string{"Google Pixel 3"},
+// drain stops new assignments by reporting no free slots, then waits for
+// the session's groups to finish, the stream to end or shutdownTimeout.
+// A drained session is closed from the agent's side so that the server
+// handles every result sent before it ends the stream.
+func (a *AppWrightAgent) drain(ctx context.Context, sess *session, recvErr <-chan error) error {
+	capacity := &pb.AgentCapacity{Slots: 0}
+	if err := sess.send(&pb.AgentMessage{Message: &pb.AgentMessage_Capacity{Capacity: capacity}}); err != nil {
+		slog.WarnContext(ctx, "Failed to stop new assignments", "error", err)
+		return nil
 	}
+	slog.InfoContext(ctx, "Draining agent session", "running_groups", sess.running(), "timeout", a.shutdownTimeout)
 
-	// Submit test to BrowserStack App Automate
-	sessionID, err := a.browserStack.StartSession(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to start BrowserStack session: %w", err)
+	timeout := time.NewTimer(a.shutdownTimeout)
+	defer timeout.Stop()
+	for sess.running() > 0 {
+		select {
+		case <-sess.idle:
+		case err := <-recvErr:
+			return err
+		case <-timeout.C:
+			slog.WarnContext(ctx, "Shutdown timed out, stopping running groups", "running_groups", sess.running())
+			return nil
+		}
 	}
+	slog.InfoContext(ctx, "Agent session drained")
 
-	// Monitor test execution
-	result, err := a.browserStack.WaitForCompletion(sessionID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to wait for test completion: %w", err)
+	if err := sess.closeSend(); err != nil {
+		return nil
+	}
+	select {
+	case err := <-recvErr:
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	case <-timeout.C:
+		return nil
 	}
+}
 
-	return result, nil
+// running returns how many of the session's groups haven't finished.
+func (s *session) running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
 }
 
-// BrowserStack API methods
-func (bs *BrowserStackClient) StartSession(payload map[string]interface{}) (string, error) {
-	url := fmt.Sprintf("%s/builds", bs.baseURL)
-	
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal payload: %w", err)
+// startGroup tracks an assigned group's jobs and queues the group for the
+// next free slot. The server assigns no more groups than the session has
+// slots, so the queue only fills if it and the agent disagree.
+func (a *AppWrightAgent) startGroup(ctx context.Context, sess *session, group *pb.GroupAssignment) {
+	groupCtx, cancel := context.WithCancel(ctx)
+	run := &groupRun{group: group, ctx: groupCtx, cancel: cancel, pending: len(group.Jobs)}
+	sess.mu.Lock()
+	for _, job := range group.Jobs {
+		sess.jobs[job.JobId] = run
 	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	sess.active++
+	sess.mu.Unlock()
+
+	select {
+	case sess.queue <- run:
+	case <-ctx.Done():
+		cancel()
+		sess.done()
 	}
+}
 
-	req.SetBasicAuth(bs.username, bs.accessKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := bs.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+// runSlot runs queued groups one at a time until the session ends. Each
+// group can be stopped on its own by cancelling its jobs.
+func (a *AppWrightAgent) runSlot(ctx context.Context, sess *session) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case run := <-sess.queue:
+			metrics.AgentSlotsBusy.Inc()
+			if run.ctx.Err() == nil {
+				a.processGroup(run.ctx, sess, run.group)
+			}
+			run.cancel()
+			// Drop anything left unreported, e.g. after a send failure
+			for _, job := range run.group.Jobs {
+				sess.release(job.JobId)
+			}
+			sess.done()
+			metrics.AgentSlotsBusy.Dec()
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to start session, status: %d", resp.StatusCode)
+// done records that a group has finished and frees its slot.
+func (s *session) done() {
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+	select {
+	case s.idle <- struct{}{}:
+	default:
 	}
+}
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
-	}
+func (a *AppWrightAgent) sendHeartbeats(ctx context.Context, sess *session) {
+	ticker := time.NewTicker(a.heartbeatInterval)
+	defer ticker.Stop()
 
-	sessionID, ok := result["session_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid session_id in response")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := sess.send(&pb.AgentMessage{Message: &pb.AgentMessage_Heartbeat{Heartbeat: &pb.AgentHeartbeat{}}}); err != nil {
+				slog.WarnContext(ctx, "Failed to send heartbeat", "error", err)
+			}
+		}
 	}
+}
 
-	return sessionID, nil
+// jobRun is one job of a running group, traced in the job's own trace
+// until its result is reported.
+type jobRun struct {
+	job    *pb.FetchJobResponse
+	target string
+	ctx    context.Context
+	span   trace.Span
 }
 
-func (bs *BrowserStackClient) WaitForCompletion(sessionID string) (*TestResult, error) {
-	url := fmt.Sprintf("%s/sessions/%s", bs.baseURL, sessionID)
-	
-	// Poll for completion
-	for i := 0; i < 60; i++ { // Max 10 minutes
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+// processGroup runs an assigned job group on the executor for its target.
+// The group's jobs share an app, so the executor prepares it once and runs
+// every job's test together; each job is reported as its test finishes.
+// Jobs the server cancels, or all of them when the session ends, stop
+// without reporting a result.
+func (a *AppWrightAgent) processGroup(ctx context.Context, sess *session, group *pb.GroupAssignment) {
+	target := targetName(group.Target)
+	ctx = logging.WithGroup(ctx, group.GroupId)
+	ctx, span := tracing.Tracer("agent").Start(ctx, "AppWrightAgent.processGroup")
+	span.SetAttributes(
+		attribute.String("group.id", group.GroupId),
+		attribute.String("group.target", target),
+		attribute.Int("group.jobs", len(group.Jobs)),
+		attribute.String("agent.id", a.agentID),
+	)
+	defer span.End()
+
+	slog.InfoContext(ctx, "Processing job group", "target", target, "app_version_id", group.AppVersionId, "jobs", len(group.Jobs))
+
+	// Continue each job's trace from the server hand-off
+	runs := make([]*jobRun, len(group.Jobs))
+	for i, job := range group.Jobs {
+		jobCtx := logging.WithJob(ctx, job.JobId, job.OrgId)
+		jobCtx, jobSpan := tracing.Tracer("agent").Start(tracing.WithTraceParent(jobCtx, job.TraceParent), "AppWrightAgent.processJob")
+		jobSpan.SetAttributes(
+			attribute.String("job.id", job.JobId),
+			attribute.String("group.id", group.GroupId),
+			attribute.String("agent.id", a.agentID),
+		)
+		runs[i] = &jobRun{job: job, target: target, ctx: jobCtx, span: jobSpan}
+	}
+	defer func() {
+		for _, run := range runs {
+			if run != nil {
+				run.span.End()
+			}
 		}
+	}()
 
-		req.SetBasicAuth(bs.username, bs.accessKey)
-
-		resp, err := bs.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to make request: %w", err)
+	for _, run := range runs {
+		if err := sess.progress(run.job.JobId, pb.Status_RUNNING); err != nil {
+			slog.ErrorContext(run.ctx, "Failed to update job to RUNNING", "error", err)
+			return // Don't proceed with jobs we can't update
 		}
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			return nil, fmt.Errorf("failed to get session status, status: %d", resp.StatusCode)
+	started := time.Now()
+	err := a.execute(ctx, target, group, func(i int, result *TestResult) {
+		if runs[i] == nil {
+			return // Already reported
 		}
+		a.finishJob(sess, runs[i], result, started)
+		runs[i] = nil
+	})
+	if err == nil {
+		err = fmt.Errorf("%s run finished without a result", target)
+	}
 
-		var session map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode response: %w", err)
+	// Jobs the run never reported fail with its error
+	for i, run := range runs {
+		if run != nil {
+			a.finishJob(sess, run, &TestResult{Status: "failed", Error: err.Error()}, started)
+			runs[i] = nil
 		}
-		resp.Body.Close()
+	}
+}
 
-		status, ok := session["status"].(string)
-		if !ok {
-			return nil, fmt.Errorf("invalid status in response")
+// execute prepares and runs a group on its target's executor, collecting
+// each test's artifacts before passing on its result. A run cut short by
+// ctx is cancelled on the executor.
+func (a *AppWrightAgent) execute(ctx context.Context, target string, group *pb.GroupAssignment, onResult func(i int, result *TestResult)) error {
+	executor, ok := a.executors[target]
+	if !ok {
+		return fmt.Errorf("agent has no executor for target %s", target)
+	}
+
+	exec, err := executor.Prepare(ctx, group)
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s run: %w", target, err)
+	}
+
+	err = executor.Run(ctx, exec, func(i int, result *TestResult) {
+		// Results come from outside the agent, e.g. BrowserStack's sessions
+		if i < 0 || i >= len(group.Jobs) {
+			slog.WarnContext(ctx, "Ignoring result for a test not in the group", "index", i, "tests", len(group.Jobs))
+			return
 		}
+		if err := executor.CollectArtifacts(ctx, exec, result); err != nil {
+			slog.WarnContext(ctx, "Failed to collect test artifacts", logging.KeyJobID, group.Jobs[i].JobId, "error", err)
+		}
+		onResult(i, result)
+	})
 
-		if status == "completed" || status == "failed" {
-			return &TestResult{
-				SessionID: sessionID,
-				Status:    status,
-				LogsURL:   fmt.Sprintf("https://app-automate.browserstack.com/dashboard/v2/builds/%s/sessions/%s", sessionID, sessionID),
-				VideoURL:  fmt.Sprintf("https://app-automate.browserstack.com/dashboard/v2/builds/%s/sessions/%s/video", sessionID, sessionID),
-			}, nil
+	if err != nil && ctx.Err() != nil {
+		cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelTimeout)
+		defer cancel()
+		if err := executor.Cancel(cancelCtx, exec); err != nil {
+			slog.WarnContext(ctx, "Failed to cancel run", "target", target, "run_id", exec.ID, "error", err)
 		}
+	}
+	return err
+}
+
+// finishJob reports a job's result, unless the job was cancelled or its
+// session has ended.
+func (a *AppWrightAgent) finishJob(sess *session, run *jobRun, result *TestResult, started time.Time) {
+	ctx, span := run.ctx, run.span
+	defer span.End()
 
-		time.Sleep(10 * time.Second)
+	if ctx.Err() != nil || !sess.release(run.job.JobId) {
+		slog.InfoContext(ctx, "Job stopped before finishing")
+		span.SetStatus(otelcodes.Error, "cancelled")
+		return
 	}
 
-	return nil, fmt.Errorf("test execution timeout")
-} 
\ No newline at end of file
+	progress := &pb.JobProgress{
+		JobId:        run.job.JobId,
+		Status:       pb.Status_COMPLETED,
+		SessionId:    result.SessionID,
+		LogsUrl:      result.LogsURL,
+		VideoUrl:     result.VideoURL,
+		TestDuration: int32(time.Since(started).Seconds()),
+	}
+	if result.Status != "completed" {
+		progress.Status = pb.Status_FAILED
+		progress.ErrorMessage = result.Error
+		if progress.ErrorMessage == "" {
+			progress.ErrorMessage = fmt.Sprintf("test %s", result.Status)
+		}
+		slog.WarnContext(ctx, "Test failed", "error", progress.ErrorMessage, "session_id", result.SessionID)
+		span.SetStatus(otelcodes.Error, progress.ErrorMessage)
+	} else {
+		slog.InfoContext(ctx, "Test completed", "session_id", result.SessionID)
+	}
+	metrics.AgentJobsProcessed.WithLabelValues(run.target, progress.Status.String()).Inc()
+	metrics.AgentTestDuration.WithLabelValues(run.target, progress.Status.String()).Observe(time.Since(started).Seconds())
+
+	if err := sess.report(progress); err != nil {
+		slog.ErrorContext(ctx, "Failed to update final status", "status", progress.Status.String(), "error", err)
+	}
+}
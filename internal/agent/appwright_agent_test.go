@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/agent/bstest"
+)
+
+// recordingStream is a session stream that records what the agent sends.
+type recordingStream struct {
+	grpc.ClientStream
+
+	mu       sync.Mutex
+	progress []*pb.JobProgress
+}
+
+func (s *recordingStream) Send(msg *pb.AgentMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if progress := msg.GetProgress(); progress != nil {
+		s.progress = append(s.progress, progress)
+	}
+	return nil
+}
+
+func (s *recordingStream) Recv() (*pb.ServerMessage, error) { return nil, io.EOF }
+
+func (s *recordingStream) CloseSend() error { return nil }
+
+// final returns each job's last reported status.
+func (s *recordingStream) final() map[string]*pb.JobProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	final := make(map[string]*pb.JobProgress)
+	for _, progress := range s.progress {
+		final[progress.JobId] = progress
+	}
+	return final
+}
+
+// assignGroup tracks group over a session on stream as the agent does when
+// the server assigns it, returning its run.
+func assignGroup(a *AppWrightAgent, stream *recordingStream, group *pb.GroupAssignment) (*session, *groupRun) {
+	sess := &session{
+		stream: stream,
+		queue:  make(chan *groupRun, 1),
+		jobs:   make(map[string]*groupRun),
+		idle:   make(chan struct{}, 1),
+	}
+	a.startGroup(context.Background(), sess, group)
+	return sess, <-sess.queue
+}
+
+func TestProcessGroupReportsResults(t *testing.T) {
+	e, _ := newTestExecutor(t, bstest.Options{
+		Result: func(app, device, test string) string {
+			if strings.Contains(test, "checkout") {
+				return "element not found"
+			}
+			return ""
+		},
+	}, nil)
+	a := &AppWrightAgent{agentID: "agent-1", executors: Executors{"browserstack": e}}
+	stream := &recordingStream{}
+	sess, run := assignGroup(a, stream, testGroup())
+
+	a.processGroup(run.ctx, sess, run.group)
+
+	final := stream.final()
+	if got := final["job-1"]; got == nil || got.Status != pb.Status_COMPLETED || got.SessionId == "" || got.LogsUrl == "" {
+		t.Errorf("job-1 reported %v, want COMPLETED with its session and logs", got)
+	}
+	if got := final["job-2"]; got == nil || got.Status != pb.Status_FAILED || got.ErrorMessage != "element not found" {
+		t.Errorf("job-2 reported %v, want FAILED with element not found", got)
+	}
+	if len(sess.jobs) != 0 {
+		t.Errorf("session still tracks %d jobs", len(sess.jobs))
+	}
+}
+
+func TestProcessGroupCancelStopsBuild(t *testing.T) {
+	e, fake := newTestExecutor(t, bstest.Options{Manual: true}, nil)
+	a := &AppWrightAgent{agentID: "agent-1", executors: Executors{"browserstack": e}}
+	stream := &recordingStream{}
+	sess, run := assignGroup(a, stream, testGroup())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.processGroup(run.ctx, sess, run.group)
+	}()
+	waitFor(t, func() bool { return len(fake.Builds()) == 1 })
+	run.cancel()
+	<-done
+
+	build := fake.Builds()[0]
+	if !build.Stopped {
+		t.Errorf("build %s was not stopped", build.ID)
+	}
+	for job, progress := range stream.final() {
+		if progress.Status != pb.Status_RUNNING {
+			t.Errorf("%s reported %s after being cancelled", job, progress.Status)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/metrics"
+)
+
+// BrowserStackClient calls the BrowserStack App Automate API.
+type BrowserStackClient struct {
+	username       string
+	accessKey      string
+	baseURL        string
+	apiRoot        string // baseURL without its /app-automate path, for uploads
+	httpClient     *http.Client
+	uploadClient   *http.Client
+	pollInterval   time.Duration
+	sessionTimeout time.Duration
+	artifactsDir   string
+
+	uploadMu sync.Mutex
+	uploads  map[string]string      // bs:// IDs by endpoint and content hash or URL
+	inflight map[string]*uploadCall // uploads in progress, by the same key
+}
+
+func NewBrowserStackClient(cfg config.BrowserStack) *BrowserStackClient {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	apiRoot, _, _ := strings.Cut(baseURL, "/app-automate")
+	return &BrowserStackClient{
+		username:       cfg.Username,
+		accessKey:      cfg.AccessKey,
+		baseURL:        baseURL,
+		apiRoot:        apiRoot,
+		httpClient:     &http.Client{Timeout: cfg.RequestTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		uploadClient:   &http.Client{Timeout: cfg.UploadTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		pollInterval:   cfg.PollInterval,
+		sessionTimeout: cfg.SessionTimeout,
+		artifactsDir:   cfg.ArtifactsDir,
+		uploads:        make(map[string]string),
+		inflight:       make(map[string]*uploadCall),
+	}
+}
+
+// BrowserStackExecutor runs a job group as one App Automate build: the
+// group's app is installed once and each job's test runs in its own
+// session of the build.
+type BrowserStackExecutor struct {
+	client *BrowserStackClient
+}
+
+func NewBrowserStackExecutor(client *BrowserStackClient) *BrowserStackExecutor {
+	return &BrowserStackExecutor{client: client}
+}
+
+// Prepare uploads the group's app and, for Espresso, each job's test-suite
+// APK, which its test_path names, unless BrowserStack already has them.
+func (e *BrowserStackExecutor) Prepare(ctx context.Context, group *pb.GroupAssignment) (*Execution, error) {
+	if group.AppVersionId == "" {
+		return nil, fmt.Errorf("browserstack jobs need an app_version_id")
+	}
+	kind := appUpload
+	for _, job := range group.Jobs {
+		if job.TestType == pb.TestType_ESPRESSO {
+			kind = espressoAppUpload
+			break
+		}
+	}
+	app, err := e.client.Upload(ctx, kind, group.AppVersionId)
+	if err != nil {
+		return nil, err
+	}
+
+	exec := &Execution{Group: group, App: app, Tests: make([]string, len(group.Jobs))}
+	for i, job := range group.Jobs {
+		exec.Tests[i] = job.TestPath
+		if job.TestType == pb.TestType_ESPRESSO {
+			if exec.Tests[i], err = e.client.Upload(ctx, testSuiteUpload, job.TestPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return exec, nil
+}
+
+func (e *BrowserStackExecutor) Run(ctx context.Context, exec *Execution, onResult func(i int, result *TestResult)) error {
+	buildID, err := e.client.StartBuild(ctx, exec.App, exec.Group.Device, exec.Tests)
+	if err != nil {
+		return fmt.Errorf("failed to start BrowserStack build: %w", err)
+	}
+	exec.ID = buildID
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("browserstack.build_id", buildID))
+	slog.InfoContext(ctx, "Started BrowserStack build", "build_id", buildID, "device", browserStackDevice(exec.Group.Device))
+
+	return e.client.WaitForBuild(ctx, buildID, len(exec.Tests), onResult)
+}
+
+func (e *BrowserStackExecutor) CollectArtifacts(ctx context.Context, exec *Execution, result *TestResult) error {
+	if result.SessionID == "" {
+		return nil
+	}
+	result.LogsURL = fmt.Sprintf("https://app-automate.browserstack.com/dashboard/v2/builds/%s/sessions/%s", exec.ID, result.SessionID)
+	result.VideoURL = fmt.Sprintf("https://app-automate.browserstack.com/dashboard/v2/builds/%s/sessions/%s/video", exec.ID, result.SessionID)
+	return nil
+}
+
+func (e *BrowserStackExecutor) Cancel(ctx context.Context, exec *Execution) error {
+	if exec.ID == "" {
+		return nil
+	}
+	return e.client.StopBuild(ctx, exec.ID)
+}
+
+// defaultDevice runs jobs submitted without a device matrix.
+const defaultDevice = "Google Pixel 3"
+
+// browserStackDevice turns a job's "name:os_version" device, such as
+// "Pixel 7:13", into BrowserStack's "name-os_version" form.
+func browserStackDevice(device string) string {
+	if device == "" {
+		return defaultDevice
+	}
+	return strings.Replace(device, ":", "-", 1)
+}
+
+// do sends a request to BrowserStack and records its latency and outcome.
+func (bs *BrowserStackClient) do(operation string, req *http.Request) (*http.Response, error) {
+	return bs.send(bs.httpClient, operation, req)
+}
+
+// send is do with a client of the caller's choosing, such as uploadClient.
+func (bs *BrowserStackClient) send(client *http.Client, operation string, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		metrics.BrowserStackErrors.WithLabelValues(operation).Inc()
+		metrics.BrowserStackRequestDuration.WithLabelValues(operation, "error").Observe(time.Since(start).Seconds())
+		return nil, err
+	}
+
+	metrics.BrowserStackRequestDuration.WithLabelValues(operation, strconv.Itoa(resp.StatusCode)).Observe(time.Since(start).Seconds())
+	if resp.StatusCode != http.StatusOK {
+		metrics.BrowserStackErrors.WithLabelValues(operation).Inc()
+	}
+	return resp, nil
+}
+
+// maxStartAttempts bounds how often StartBuild tries when BrowserStack is
+// rate limiting or failing.
+const maxStartAttempts = 3
+
+// transient reports whether a response status is worth retrying: rate
+// limiting or an error on BrowserStack's side.
+func transient(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryDelay returns how long to wait before retrying a response: its
+// Retry-After seconds, or fallback.
+func retryDelay(resp *http.Response, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// StartBuild starts one build that installs app, a bs:// ID from Upload,
+// once on device and runs each test in its own session, returning the
+// build ID. It retries while BrowserStack
+// is rate limiting or failing.
+func (bs *BrowserStackClient) StartBuild(ctx context.Context, app, device string, tests []string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		buildID, retryIn, err := bs.startBuild(ctx, app, device, tests)
+		if retryIn == 0 || attempt == maxStartAttempts {
+			return buildID, err
+		}
+		slog.WarnContext(ctx, "BrowserStack busy, retrying build", "error", err, "retry_in", retryIn)
+		if err := sleepCtx(ctx, retryIn); err != nil {
+			return "", err
+		}
+	}
+}
+
+// startBuild makes one attempt at StartBuild. A non-zero retryIn means the
+// attempt failed transiently.
+func (bs *BrowserStackClient) startBuild(ctx context.Context, app, device string, tests []string) (buildID string, retryIn time.Duration, err error) {
+	url := fmt.Sprintf("%s/builds", bs.baseURL)
+
+	payload := map[string]interface{}{
+		"app":     app,
+		"devices": []string{browserStackDevice(device)},
+		"tests":   tests,
+	}
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.SetBasicAuth(bs.username, bs.accessKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := bs.do("start_build", req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to start build, status: %d", resp.StatusCode)
+		if transient(resp.StatusCode) {
+			return "", max(retryDelay(resp, bs.pollInterval), time.Millisecond), err
+		}
+		return "", 0, err
+	}
+
+	var result struct {
+		BuildID string `json:"build_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.BuildID == "" {
+		return "", 0, fmt.Errorf("invalid build_id in response")
+	}
+
+	return result.BuildID, 0, nil
+}
+
+// WaitForBuild polls a build of the given number of tests until every
+// test's session has finished, calling onResult once for each as it does.
+// Sessions are listed in the order the tests were given to StartBuild, and
+// i is that index.
+func (bs *BrowserStackClient) WaitForBuild(ctx context.Context, buildID string, tests int, onResult func(i int, result *TestResult)) error {
+	url := fmt.Sprintf("%s/builds/%s", bs.baseURL, buildID)
+	reported := make(map[int]bool)
+
+	// Poll for completion
+	deadline := time.Now().Add(bs.sessionTimeout)
+	for time.Now().Before(deadline) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.SetBasicAuth(bs.username, bs.accessKey)
+
+		resp, err := bs.do("get_build", req)
+		if err != nil {
+			return fmt.Errorf("failed to make request: %w", err)
+		}
+
+		if transient(resp.StatusCode) {
+			// Keep polling; the build carries on regardless
+			resp.Body.Close()
+			slog.DebugContext(ctx, "BrowserStack busy, polling build again", "build_id", buildID, "status", resp.StatusCode)
+			if err := sleepCtx(ctx, max(retryDelay(resp, bs.pollInterval), bs.pollInterval)); err != nil {
+				return err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("failed to get build status, status: %d", resp.StatusCode)
+		}
+
+		var build struct {
+			Status   string `json:"status"`
+			Sessions []struct {
+				SessionID string `json:"session_id"`
+				Status    string `json:"status"`
+				Error     string `json:"error"`
+			} `json:"sessions"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&build); err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		resp.Body.Close()
+
+		if len(build.Sessions) > 0 && len(build.Sessions) != tests {
+			return fmt.Errorf("build has %d sessions for %d tests", len(build.Sessions), tests)
+		}
+		for i, session := range build.Sessions {
+			if reported[i] || (session.Status != "completed" && session.Status != "failed") {
+				continue
+			}
+			reported[i] = true
+			onResult(i, &TestResult{
+				SessionID: session.SessionID,
+				Status:    session.Status,
+				Error:     session.Error,
+			})
+		}
+		if len(build.Sessions) > 0 && len(reported) == len(build.Sessions) {
+			return nil
+		}
+		if build.Status == "failed" || build.Status == "error" {
+			return fmt.Errorf("build %s", build.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(bs.pollInterval):
+		}
+	}
+
+	return fmt.Errorf("test execution timeout")
+}
+
+// StopBuild stops a running build and its sessions.
+func (bs *BrowserStackClient) StopBuild(ctx context.Context, buildID string) error {
+	url := fmt.Sprintf("%s/builds/%s/stop", bs.baseURL, buildID)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.SetBasicAuth(bs.username, bs.accessKey)
+
+	resp, err := bs.do("stop_build", req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to stop build, status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/agent/bstest"
+	"qualgent-test-platform/internal/config"
+)
+
+// newTestExecutor runs a BrowserStackExecutor against a fake App Automate
+// API, with an app.apk in its artifacts directory. configure, if not nil,
+// adjusts the client's settings.
+func newTestExecutor(t *testing.T, opts bstest.Options, configure func(*config.BrowserStack)) (*BrowserStackExecutor, *bstest.Fake) {
+	t.Helper()
+	opts.Username, opts.AccessKey = "user", "key"
+	srv, fake := bstest.NewServer(opts)
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.apk"), []byte("apk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultAgent().BrowserStack
+	cfg.Username, cfg.AccessKey = "user", "key"
+	cfg.BaseURL = srv.URL
+	cfg.ArtifactsDir = dir
+	cfg.PollInterval = 10 * time.Millisecond
+	cfg.SessionTimeout = 10 * time.Second
+	if configure != nil {
+		configure(&cfg)
+	}
+	return NewBrowserStackExecutor(NewBrowserStackClient(cfg)), fake
+}
+
+func testGroup() *pb.GroupAssignment {
+	return &pb.GroupAssignment{
+		GroupId:      "group-1",
+		AppVersionId: "app.apk",
+		Target:       pb.Target_BROWSERSTACK,
+		Device:       "Pixel 7:13",
+		Jobs: []*pb.FetchJobResponse{
+			{JobId: "job-1", TestPath: "tests/login.spec.js"},
+			{JobId: "job-2", TestPath: "tests/checkout.spec.js"},
+		},
+	}
+}
+
+// runGroup prepares and runs group, returning its results by job index.
+func runGroup(ctx context.Context, t *testing.T, e *BrowserStackExecutor, group *pb.GroupAssignment) (*Execution, map[int]*TestResult, error) {
+	t.Helper()
+	exec, err := e.Prepare(ctx, group)
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	results := make(map[int]*TestResult)
+	err = e.Run(ctx, exec, func(i int, result *TestResult) {
+		if results[i] != nil {
+			t.Errorf("test %d reported twice", i)
+		}
+		results[i] = result
+	})
+	return exec, results, err
+}
+
+func TestBrowserStackExecutorRunsBuild(t *testing.T) {
+	e, fake := newTestExecutor(t, bstest.Options{}, nil)
+
+	exec, results, err := runGroup(context.Background(), t, e, testGroup())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for i := range 2 {
+		if results[i] == nil || results[i].Status != "completed" {
+			t.Errorf("test %d result = %+v, want completed", i, results[i])
+		}
+	}
+
+	build, ok := fake.Build(exec.ID)
+	if !ok {
+		t.Fatalf("no build %s", exec.ID)
+	}
+	if build.App != exec.App || len(build.Sessions) != 2 {
+		t.Errorf("build = %+v, want app %s and 2 sessions", build, exec.App)
+	}
+	if len(build.Devices) != 1 || build.Devices[0] != "Pixel 7-13" {
+		t.Errorf("build devices = %v, want [Pixel 7-13]", build.Devices)
+	}
+	if uploads := fake.Uploads(); len(uploads) != 1 || uploads[0].Kind != "app" {
+		t.Errorf("uploads = %+v, want the app once", uploads)
+	}
+}
+
+func TestBrowserStackExecutorFailedSession(t *testing.T) {
+	e, _ := newTestExecutor(t, bstest.Options{
+		Result: func(app, device, test string) string {
+			if strings.Contains(test, "checkout") {
+				return "element not found"
+			}
+			return ""
+		},
+	}, nil)
+
+	_, results, err := runGroup(context.Background(), t, e, testGroup())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if results[0] == nil || results[0].Status != "completed" {
+		t.Errorf("login result = %+v, want completed", results[0])
+	}
+	if results[1] == nil || results[1].Status != "failed" || results[1].Error != "element not found" {
+		t.Errorf("checkout result = %+v, want failed with element not found", results[1])
+	}
+}
+
+func TestBrowserStackExecutorRetriesRateLimitedStart(t *testing.T) {
+	e, fake := newTestExecutor(t, bstest.Options{}, nil)
+
+	exec, err := e.Prepare(context.Background(), testGroup())
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	fake.FailNext(1, http.StatusTooManyRequests)
+	if err := e.Run(context.Background(), exec, func(int, *TestResult) {}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if builds := fake.Builds(); len(builds) != 1 {
+		t.Errorf("started %d builds, want 1", len(builds))
+	}
+}
+
+func TestBrowserStackExecutorRequestTimeout(t *testing.T) {
+	// Uploads have their own, longer timeout, so only the build times out
+	e, _ := newTestExecutor(t, bstest.Options{Latency: 200 * time.Millisecond}, func(cfg *config.BrowserStack) {
+		cfg.RequestTimeout = 50 * time.Millisecond
+	})
+
+	exec, err := e.Prepare(context.Background(), testGroup())
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+
+	start := time.Now()
+	err = e.Run(context.Background(), exec, func(int, *TestResult) {})
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Run error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("Run took %s, want it cut off by the request timeout", elapsed)
+	}
+}
+
+func TestBrowserStackExecutorCancelStopsBuild(t *testing.T) {
+	e, fake := newTestExecutor(t, bstest.Options{Manual: true}, nil)
+
+	exec, err := e.Prepare(context.Background(), testGroup())
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Run(ctx, exec, func(int, *TestResult) {})
+	}()
+
+	waitFor(t, func() bool { return len(fake.Builds()) == 1 })
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want context.Canceled", err)
+	}
+
+	if err := e.Cancel(context.Background(), exec); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	build, _ := fake.Build(exec.ID)
+	if !build.Stopped {
+		t.Fatalf("build %s was not stopped", exec.ID)
+	}
+	for i, session := range build.Sessions {
+		if session.Status != bstest.StatusFailed {
+			t.Errorf("session %d status = %s, want failed", i, session.Status)
+		}
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBrowserStackUploadStaysInArtifactsDir(t *testing.T) {
+	var dir string
+	e, fake := newTestExecutor(t, bstest.Options{}, func(cfg *config.BrowserStack) { dir = cfg.ArtifactsDir })
+	if err := os.Symlink("/dev/zero", filepath.Join(dir, "zero.apk")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, app := range []string{"/etc/passwd", "../../etc/passwd", "zero.apk"} {
+		group := testGroup()
+		group.AppVersionId = app
+		if _, err := e.Prepare(context.Background(), group); err == nil {
+			t.Errorf("Prepare uploaded %s", app)
+		}
+	}
+	group := testGroup()
+	group.AppVersionId = "../app.apk"
+	if _, err := e.Prepare(context.Background(), group); err != nil {
+		t.Errorf("Prepare(../app.apk) = %v, want the artifacts directory's app.apk", err)
+	}
+	if uploads := fake.Uploads(); len(uploads) != 1 || uploads[0].Name != "app.apk" {
+		t.Errorf("uploads = %+v, want only app.apk", uploads)
+	}
+}
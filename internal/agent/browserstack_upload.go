@@ -0,0 +1,224 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"qualgent-test-platform/internal/artifact"
+	"qualgent-test-platform/internal/metrics"
+)
+
+// uploadKind is something BrowserStack needs uploaded before a build can
+// use it: the app under test, or an Espresso test suite.
+type uploadKind struct {
+	name     string // for logs and metrics
+	endpoint string // path under the API root
+	field    string // response field holding the bs:// ID
+}
+
+// Appium and Espresso builds take apps from different upload endpoints, and
+// an app ID from one is not accepted by the other.
+var (
+	appUpload         = uploadKind{name: "app", endpoint: "/app-automate/upload", field: "app_url"}
+	espressoAppUpload = uploadKind{name: "app", endpoint: "/app-automate/espresso/v2/app", field: "app_url"}
+	testSuiteUpload   = uploadKind{name: "test_suite", endpoint: "/app-automate/espresso/v2/test-suite", field: "test_suite_url"}
+)
+
+// Upload readies an app or test suite for a build and returns its bs:// ID.
+// A bs:// ID is returned as it is. A URL is handed to BrowserStack to fetch
+// and a local path, resolved against the artifacts directory, is uploaded.
+// IDs are cached for the agent's lifetime, keyed by the file's content hash
+// or the URL, so each distinct APK is uploaded once: concurrent groups of the
+// same app wait for one upload rather than each starting their own.
+func (bs *BrowserStackClient) Upload(ctx context.Context, kind uploadKind, source string) (string, error) {
+	if strings.HasPrefix(source, "bs://") {
+		return source, nil
+	}
+
+	var key, path string
+	if artifact.IsURL(source) {
+		key = source
+	} else {
+		path = artifact.Path(bs.artifactsDir, source)
+		sum, err := hashFile(ctx, path)
+		if err != nil {
+			return "", err
+		}
+		key = "sha256:" + sum
+	}
+	key = kind.endpoint + ":" + key
+
+	for {
+		bs.uploadMu.Lock()
+		if id, ok := bs.uploads[key]; ok {
+			bs.uploadMu.Unlock()
+			metrics.BrowserStackUploads.WithLabelValues(kind.name, "cached").Inc()
+			slog.DebugContext(ctx, "Using cached BrowserStack upload", "kind", kind.name, "source", source, "id", id)
+			return id, nil
+		}
+		call, waiting := bs.inflight[key]
+		if !waiting {
+			call = &uploadCall{done: make(chan struct{})}
+			bs.inflight[key] = call
+		}
+		bs.uploadMu.Unlock()
+
+		if !waiting {
+			return bs.lead(ctx, call, key, kind, source, path)
+		}
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		if call.err == nil {
+			metrics.BrowserStackUploads.WithLabelValues(kind.name, "cached").Inc()
+			slog.DebugContext(ctx, "Using BrowserStack upload from another group", "kind", kind.name, "source", source, "id", call.id)
+			return call.id, nil
+		}
+		// An upload abandoned because its own group was cancelled says
+		// nothing about this one, so try again
+		if !call.abandoned {
+			return "", call.err
+		}
+	}
+}
+
+// uploadCall is an upload in progress, which other groups needing the same
+// key wait on.
+type uploadCall struct {
+	done      chan struct{}
+	id        string
+	err       error
+	abandoned bool // the uploading group's context ended
+}
+
+// lead performs the upload for call and hands its outcome to any waiters.
+func (bs *BrowserStackClient) lead(ctx context.Context, call *uploadCall, key string, kind uploadKind, source, path string) (string, error) {
+	call.id, call.err = bs.upload(ctx, kind, source, path)
+	call.abandoned = call.err != nil && ctx.Err() != nil
+
+	bs.uploadMu.Lock()
+	delete(bs.inflight, key)
+	if call.err == nil {
+		bs.uploads[key] = call.id
+	}
+	bs.uploadMu.Unlock()
+	close(call.done)
+
+	if call.err != nil {
+		return "", call.err
+	}
+	metrics.BrowserStackUploads.WithLabelValues(kind.name, "uploaded").Inc()
+	slog.InfoContext(ctx, "Uploaded to BrowserStack", "kind", kind.name, "source", source, "id", call.id)
+	return call.id, nil
+}
+
+// upload sends one app or test suite: the file at path as multipart form
+// data, or source as a url field if path is empty.
+func (bs *BrowserStackClient) upload(ctx context.Context, kind uploadKind, source, path string) (string, error) {
+	body, contentType, err := uploadBody(source, path)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", bs.apiRoot+kind.endpoint, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(bs.username, bs.accessKey)
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := bs.send(bs.uploadClient, "upload_"+kind.name, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", kind.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to upload %s, status: %d", kind.name, resp.StatusCode)
+	}
+
+	var result map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	id, _ := result[kind.field].(string)
+	if !strings.HasPrefix(id, "bs://") {
+		return "", fmt.Errorf("invalid %s in response", kind.field)
+	}
+	return id, nil
+}
+
+// uploadBody streams the multipart form for an upload, so large APKs are
+// never held in memory.
+func uploadBody(source, path string) (io.ReadCloser, string, error) {
+	var file *os.File
+	if path != "" {
+		var err error
+		if file, err = artifact.Open(path); err != nil {
+			return nil, "", fmt.Errorf("failed to open %s: %w", source, err)
+		}
+	}
+
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	go func() {
+		var err error
+		if file != nil {
+			defer file.Close()
+			var part io.Writer
+			if part, err = form.CreateFormFile("file", filepath.Base(path)); err == nil {
+				_, err = io.Copy(part, file)
+			}
+		} else {
+			err = form.WriteField("url", source)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, form.FormDataContentType(), nil
+}
+
+// hashFile returns the hex SHA-256 of a regular file's content, giving up
+// once ctx is done.
+func hashFile(ctx context.Context, path string) (string, error) {
+	f, err := artifact.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, ctxReader{ctx, f}); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ctxReader stops reading once ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,485 @@
+// Package bstest provides an in-process fake of the BrowserStack App
+// Automate API, so the agent can be run and tested without BrowserStack:
+// point browserstack.base_url (BROWSERSTACK_BASE_URL) at a Server's URL.
+//
+// The fake serves the calls the agent makes:
+//
+//	POST /app-automate/upload                  upload an app, returning its bs:// ID
+//	POST /app-automate/espresso/v2/app         upload an app for Espresso
+//	POST /app-automate/espresso/v2/test-suite  upload an Espresso test suite
+//	POST /builds                               start a build, one session per test
+//	GET  /builds/{id}                          the build and its sessions
+//	GET  /builds/{id}/sessions/{sid}           one session
+//	POST /builds/{id}/stop                     stop the build's unfinished sessions
+//
+// Builds must name an uploaded app. Sessions move from queued to running to completed or failed on a clock
+// set by Options, or by hand with SetSession when Options.Manual is set.
+// Errors, rate limiting and slow responses can be injected.
+package bstest
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session statuses, as App Automate reports them.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Build statuses.
+const (
+	BuildRunning = "running"
+	BuildDone    = "done"
+	BuildFailed  = "failed"
+)
+
+// Options configures a Fake. The zero value accepts any credentials and
+// finishes every session successfully as soon as it is polled.
+type Options struct {
+	// Username and AccessKey, when set, are required as basic auth.
+	Username  string
+	AccessKey string
+
+	// QueueDelay is how long a session stays queued, and SessionDuration
+	// how long it then runs.
+	QueueDelay      time.Duration
+	SessionDuration time.Duration
+
+	// Manual leaves every session queued until SetSession moves it.
+	Manual bool
+
+	// Result decides how a session of test on device ends: an empty string
+	// completes it and anything else fails it with that error. Nil
+	// completes all. Uploaded apps and test suites are passed as their file
+	// names or URLs.
+	Result func(app, device, test string) string
+
+	// Latency delays every response.
+	Latency time.Duration
+
+	// RateLimit, when positive, allows that many requests per RateWindow
+	// (default one second) and answers the rest with 429 and Retry-After.
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// Session is a snapshot of one session of a build.
+type Session struct {
+	ID     string `json:"session_id"`
+	Test   string `json:"test"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	start    time.Time
+	finished bool
+}
+
+// Upload is one app or test suite uploaded to the fake.
+type Upload struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"` // "app" or "test-suite"
+	// Name is the uploaded file's name, or the URL it was fetched from
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Build is a snapshot of one build.
+type Build struct {
+	ID       string    `json:"build_id"`
+	App      string    `json:"app"`
+	Devices  []string  `json:"devices"`
+	Status   string    `json:"status"`
+	Stopped  bool      `json:"stopped"`
+	Sessions []Session `json:"sessions"`
+}
+
+// Fake is a fake App Automate API. It is an http.Handler; use NewServer to
+// serve it on a local port.
+type Fake struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu          sync.Mutex
+	nextID      int
+	builds      map[string]*Build
+	order       []string
+	uploads     []Upload
+	uploaded    map[string]Upload
+	failures    []int
+	windowStart time.Time
+	windowCount int
+	requests    int
+}
+
+func New(opts Options) *Fake {
+	if opts.RateWindow <= 0 {
+		opts.RateWindow = time.Second
+	}
+	f := &Fake{
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		builds:   make(map[string]*Build),
+		uploaded: make(map[string]Upload),
+	}
+	f.mux.HandleFunc("POST /app-automate/upload", f.upload("app", "app_url"))
+	f.mux.HandleFunc("POST /app-automate/espresso/v2/app", f.upload("app", "app_url"))
+	f.mux.HandleFunc("POST /app-automate/espresso/v2/test-suite", f.upload("test-suite", "test_suite_url"))
+	f.mux.HandleFunc("POST /builds", f.startBuild)
+	f.mux.HandleFunc("GET /builds/{id}", f.getBuild)
+	f.mux.HandleFunc("GET /builds/{id}/sessions/{sid}", f.getSession)
+	f.mux.HandleFunc("POST /builds/{id}/stop", f.stopBuild)
+	return f
+}
+
+// NewServer starts a Fake on a local httptest server. Its URL is the
+// base_url to give the agent; Close it when done.
+func NewServer(opts Options) (*httptest.Server, *Fake) {
+	f := New(opts)
+	return httptest.NewServer(f), f
+}
+
+// FailNext answers the next n requests with status code, ahead of any
+// other handling.
+func (f *Fake) FailNext(n int, code int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for range n {
+		f.failures = append(f.failures, code)
+	}
+}
+
+// SetSession moves session i of a build to status, failing it with errMsg
+// if status is StatusFailed.
+func (f *Fake) SetSession(buildID string, i int, status, errMsg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	build, ok := f.builds[buildID]
+	if !ok {
+		return fmt.Errorf("no build %s", buildID)
+	}
+	if i < 0 || i >= len(build.Sessions) {
+		return fmt.Errorf("build %s has no session %d", buildID, i)
+	}
+	session := &build.Sessions[i]
+	session.Status = status
+	session.Error = ""
+	if status == StatusFailed {
+		session.Error = errMsg
+	}
+	session.finished = status == StatusCompleted || status == StatusFailed
+	return nil
+}
+
+// Builds returns every build started so far, oldest first.
+func (f *Fake) Builds() []Build {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	builds := make([]Build, 0, len(f.order))
+	for _, id := range f.order {
+		builds = append(builds, f.snapshotLocked(f.builds[id], time.Now()))
+	}
+	return builds
+}
+
+// Build returns one build, if it was started.
+func (f *Fake) Build(id string) (Build, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	build, ok := f.builds[id]
+	if !ok {
+		return Build{}, false
+	}
+	return f.snapshotLocked(build, time.Now()), true
+}
+
+// Uploads returns every upload so far, oldest first, including repeated
+// uploads of the same content.
+func (f *Fake) Uploads() []Upload {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Upload(nil), f.uploads...)
+}
+
+// Requests returns how many requests the fake has received.
+func (f *Fake) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.opts.Latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(f.opts.Latency):
+		}
+	}
+
+	f.mu.Lock()
+	f.requests++
+	var failure int
+	if len(f.failures) > 0 {
+		failure, f.failures = f.failures[0], f.failures[1:]
+	}
+	retryAfter := f.rateLimitLocked(time.Now())
+	f.mu.Unlock()
+
+	if failure != 0 {
+		writeError(w, failure, "injected failure")
+		return
+	}
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+	if !f.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+	f.mux.ServeHTTP(w, r)
+}
+
+// rateLimitLocked counts a request against the current window and returns
+// how long until the next one if it is over the limit.
+func (f *Fake) rateLimitLocked(now time.Time) time.Duration {
+	if f.opts.RateLimit <= 0 {
+		return 0
+	}
+	if now.Sub(f.windowStart) >= f.opts.RateWindow {
+		f.windowStart = now
+		f.windowCount = 0
+	}
+	f.windowCount++
+	if f.windowCount <= f.opts.RateLimit {
+		return 0
+	}
+	return f.windowStart.Add(f.opts.RateWindow).Sub(now)
+}
+
+func (f *Fake) authorized(r *http.Request) bool {
+	if f.opts.Username == "" && f.opts.AccessKey == "" {
+		return true
+	}
+	username, accessKey, ok := r.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(f.opts.Username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(accessKey), []byte(f.opts.AccessKey)) == 1
+}
+
+// upload accepts a multipart form with either a file or a url field, as
+// App Automate does. Its ID is derived from the kind and content, so
+// uploading the same file again returns the same ID.
+func (f *Fake) upload(kind, field string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		upload := Upload{Kind: kind}
+		h := sha256.New()
+		io.WriteString(h, kind+":")
+		if file, header, err := r.FormFile("file"); err == nil {
+			defer file.Close()
+			if upload.Size, err = io.Copy(h, file); err != nil {
+				writeError(w, http.StatusBadRequest, "failed to read file")
+				return
+			}
+			upload.Name = header.Filename
+		} else if u := r.FormValue("url"); u != "" {
+			io.WriteString(h, u)
+			upload.Name = u
+		} else {
+			writeError(w, http.StatusUnprocessableEntity, "file or url is required")
+			return
+		}
+		upload.ID = "bs://" + hex.EncodeToString(h.Sum(nil))[:40]
+
+		f.mu.Lock()
+		f.uploads = append(f.uploads, upload)
+		f.uploaded[upload.ID] = upload
+		f.mu.Unlock()
+		writeJSON(w, map[string]string{field: upload.ID})
+	}
+}
+
+func (f *Fake) startBuild(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		App     string   `json:"app"`
+		Devices []string `json:"devices"`
+		Tests   []string `json:"tests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.App == "" {
+		writeError(w, http.StatusUnprocessableEntity, "app is required")
+		return
+	}
+	if len(req.Tests) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "tests are required")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if upload, ok := f.uploaded[req.App]; !ok || upload.Kind != "app" {
+		writeError(w, http.StatusUnprocessableEntity, "app "+req.App+" has not been uploaded")
+		return
+	}
+	for _, test := range req.Tests {
+		if upload, ok := f.uploaded[test]; strings.HasPrefix(test, "bs://") && (!ok || upload.Kind != "test-suite") {
+			writeError(w, http.StatusUnprocessableEntity, "test suite "+test+" has not been uploaded")
+			return
+		}
+	}
+	f.nextID++
+	build := &Build{
+		ID:      fmt.Sprintf("build-%d", f.nextID),
+		App:     req.App,
+		Devices: req.Devices,
+	}
+	now := time.Now()
+	for i, test := range req.Tests {
+		build.Sessions = append(build.Sessions, Session{
+			ID:     fmt.Sprintf("%s-session-%d", build.ID, i),
+			Test:   test,
+			Status: StatusQueued,
+			start:  now,
+		})
+	}
+	f.builds[build.ID] = build
+	f.order = append(f.order, build.ID)
+	writeJSON(w, map[string]string{"build_id": build.ID})
+}
+
+func (f *Fake) getBuild(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	build, ok := f.builds[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "build not found")
+		return
+	}
+	writeJSON(w, f.snapshotLocked(build, time.Now()))
+}
+
+func (f *Fake) getSession(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	build, ok := f.builds[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "build not found")
+		return
+	}
+	snapshot := f.snapshotLocked(build, time.Now())
+	for _, session := range snapshot.Sessions {
+		if session.ID == r.PathValue("sid") {
+			writeJSON(w, session)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "session not found")
+}
+
+func (f *Fake) stopBuild(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	build, ok := f.builds[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "build not found")
+		return
+	}
+	f.advanceLocked(build, time.Now())
+	build.Stopped = true
+	for i := range build.Sessions {
+		session := &build.Sessions[i]
+		if !session.finished {
+			session.Status = StatusFailed
+			session.Error = "build stopped"
+			session.finished = true
+		}
+	}
+	writeJSON(w, map[string]string{"message": "build stopped"})
+}
+
+// snapshotLocked advances build's sessions to now and copies it out.
+func (f *Fake) snapshotLocked(build *Build, now time.Time) Build {
+	f.advanceLocked(build, now)
+	snapshot := *build
+	snapshot.Sessions = append([]Session(nil), build.Sessions...)
+	snapshot.Status = BuildDone
+	if build.Stopped {
+		snapshot.Status = BuildFailed
+	}
+	for _, session := range snapshot.Sessions {
+		if !session.finished {
+			snapshot.Status = BuildRunning
+		}
+	}
+	return snapshot
+}
+
+// advanceLocked moves build's sessions along the clock set by Options.
+func (f *Fake) advanceLocked(build *Build, now time.Time) {
+	if f.opts.Manual || build.Stopped {
+		return
+	}
+	for i := range build.Sessions {
+		session := &build.Sessions[i]
+		if session.finished {
+			continue
+		}
+		elapsed := now.Sub(session.start)
+		switch {
+		case elapsed < f.opts.QueueDelay:
+			session.Status = StatusQueued
+		case elapsed < f.opts.QueueDelay+f.opts.SessionDuration:
+			session.Status = StatusRunning
+		default:
+			session.Status = StatusCompleted
+			if f.opts.Result != nil {
+				var device string
+				if len(build.Devices) > 0 {
+					device = build.Devices[0]
+				}
+				if errMsg := f.opts.Result(f.name(build.App), device, f.name(session.Test)); errMsg != "" {
+					session.Status = StatusFailed
+					session.Error = errMsg
+				}
+			}
+			session.finished = true
+		}
+	}
+}
+
+// name returns what an uploaded app or test suite was uploaded as.
+func (f *Fake) name(id string) string {
+	if upload, ok := f.uploaded[id]; ok {
+		return upload.Name
+	}
+	return id
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
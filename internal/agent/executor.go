@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	pb "qualgent-test-platform/api/proto"
+)
+
+// Executor runs job groups for one target. For each group the agent calls
+// Prepare and then Run. As each test finishes, CollectArtifacts fills in
+// its logs and video before the job is reported. If the group is stopped
+// before Run returns, e.g. because the server cancelled its jobs, the agent
+// calls Cancel so nothing is left running on the executor's side.
+type Executor interface {
+	// Prepare readies what the group's tests need, such as its app, and
+	// returns the group's execution.
+	Prepare(ctx context.Context, group *pb.GroupAssignment) (*Execution, error)
+	// Run runs the group's tests, calling onResult once for each test as
+	// it finishes; i indexes the group's jobs. It returns once every test
+	// has finished, or with the error that stopped the rest.
+	Run(ctx context.Context, exec *Execution, onResult func(i int, result *TestResult)) error
+	// CollectArtifacts fills in a finished test's artifact URLs.
+	CollectArtifacts(ctx context.Context, exec *Execution, result *TestResult) error
+	// Cancel stops an execution whose context was cancelled.
+	Cancel(ctx context.Context, exec *Execution) error
+}
+
+// Execution is one job group being run by an executor.
+type Execution struct {
+	Group *pb.GroupAssignment
+	// ID identifies the run to the executor, such as a BrowserStack build
+	// ID. It is empty until Run starts the run.
+	ID string
+	// App and Tests are the group's app and its jobs' tests as Prepare
+	// readied them for Run, such as BrowserStack's bs:// IDs for uploads.
+	// Executors that need nothing readied leave them empty.
+	App   string
+	Tests []string
+}
+
+// Executors maps each target an agent runs jobs for, such as
+// "browserstack" or "web", to its executor.
+type Executors map[string]Executor
+
+// capability returns the targets as the agent's target_capability.
+func (e Executors) capability() string {
+	targets := make([]string, 0, len(e))
+	for target := range e {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return strings.Join(targets, ",")
+}
+
+// targetName returns a job target as executors are keyed, e.g.
+// "browserstack".
+func targetName(target pb.Target) string {
+	return strings.ToLower(target.String())
+}
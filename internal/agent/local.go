@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/logging"
+)
+
+// maxErrorLength bounds the output quoted in a failed job's error message.
+const maxErrorLength = 256
+
+// LocalExecutor runs each job of a group as a local command, one after
+// another, such as `npx playwright test "$QG_TEST_PATH"` against the job's
+// web_app_url, or an instrumentation run on an attached emulator or device
+// for those targets. Every job gets a working directory of its own under workDir,
+// where the command runs and its output is kept as output.log. The job
+// completes if the command exits 0 and fails otherwise. Directories older
+// than retention are removed as new groups are prepared.
+type LocalExecutor struct {
+	command   string
+	workDir   string
+	timeout   time.Duration
+	retention time.Duration
+}
+
+// NewLocalExecutor returns an executor running target's command.
+func NewLocalExecutor(cfg config.Local, target string) *LocalExecutor {
+	return &LocalExecutor{
+		command:   cfg.CommandFor(target),
+		workDir:   cfg.WorkDir,
+		timeout:   cfg.Timeout,
+		retention: cfg.Retention,
+	}
+}
+
+func (e *LocalExecutor) Prepare(ctx context.Context, group *pb.GroupAssignment) (*Execution, error) {
+	if group.Target == pb.Target_WEB && group.WebAppUrl == "" {
+		return nil, fmt.Errorf("web jobs need a web_app_url")
+	}
+	if err := os.MkdirAll(e.workDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create work dir: %w", err)
+	}
+	e.prune(ctx)
+	return &Execution{Group: group, ID: group.GroupId}, nil
+}
+
+// prune removes the job directories under workDir last changed more than
+// retention ago. Their logs and videos go with them.
+func (e *LocalExecutor) prune(ctx context.Context) {
+	if e.retention <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(e.workDir)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to list old job dirs", "dir", e.workDir, "error", err)
+		return
+	}
+	cutoff := time.Now().Add(-e.retention)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(e.workDir, entry.Name())); err != nil {
+			slog.WarnContext(ctx, "Failed to remove old job dir", "dir", entry.Name(), "error", err)
+		}
+	}
+}
+
+func (e *LocalExecutor) Run(ctx context.Context, exec *Execution, onResult func(i int, result *TestResult)) error {
+	for i, job := range exec.Group.Jobs {
+		result, err := e.runJob(logging.WithJob(ctx, job.JobId, job.OrgId), exec.Group, job)
+		if err != nil {
+			return err
+		}
+		onResult(i, result)
+	}
+	return nil
+}
+
+// runJob runs one job's command. It returns an error only when the command
+// couldn't be run at all or ctx was cancelled; a command that fails is the
+// job's result.
+func (e *LocalExecutor) runJob(ctx context.Context, group *pb.GroupAssignment, job *pb.FetchJobResponse) (*TestResult, error) {
+	dir := filepath.Join(e.workDir, job.JobId)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job dir: %w", err)
+	}
+	logPath := filepath.Join(dir, "output.log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job log: %w", err)
+	}
+	defer logFile.Close()
+
+	runCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := execCommand(runCtx, e.command)
+	cmd.Dir = dir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Env = append(os.Environ(),
+		"QG_JOB_ID="+job.JobId,
+		"QG_GROUP_ID="+group.GroupId,
+		"QG_ORG_ID="+job.OrgId,
+		"QG_TARGET="+targetName(job.Target),
+		"QG_TEST_PATH="+job.TestPath,
+		"QG_APP_VERSION_ID="+job.AppVersionId,
+		"QG_WEB_APP_URL="+job.WebAppUrl,
+		"QG_PRIORITY="+strconv.Itoa(int(job.Priority)),
+		"QG_WORK_DIR="+dir,
+	)
+
+	slog.InfoContext(ctx, "Running local command", "dir", dir)
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// A local run's session is its job, which names its working directory
+	result := &TestResult{SessionID: job.JobId, Status: "completed"}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("command timed out after %s", e.timeout)
+	case errors.As(err, &exitErr):
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("command exited with code %d", exitErr.ExitCode())
+		if line := lastLine(logPath); line != "" {
+			result.Error += ": " + line
+		}
+	default:
+		return nil, fmt.Errorf("failed to run command: %w", err)
+	}
+	return result, nil
+}
+
+// CollectArtifacts points the job's logs at its output.log and its video
+// at the first recording the command left in its working directory, such
+// as Playwright's test-results/*/video.webm.
+func (e *LocalExecutor) CollectArtifacts(ctx context.Context, exec *Execution, result *TestResult) error {
+	dir := filepath.Join(e.workDir, result.SessionID)
+	result.LogsURL = "file://" + filepath.Join(dir, "output.log")
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".webm", ".mp4":
+			result.VideoURL = "file://" + path
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to look for videos: %w", err)
+	}
+	return nil
+}
+
+// Cancel has nothing left to stop: cancelling Run's context kills the
+// running command.
+func (e *LocalExecutor) Cancel(ctx context.Context, exec *Execution) error {
+	return nil
+}
+
+// lastLine returns the last non-empty line of a command's output, cut to
+// maxErrorLength.
+func lastLine(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Size() > 4096 {
+		f.Seek(-4096, io.SeekEnd)
+	}
+	tail, err := io.ReadAll(f)
+	if err != nil {
+		return ""
+	}
+	lines := bytes.Split(bytes.TrimSpace(tail), []byte("\n"))
+	line := strings.TrimSpace(string(lines[len(lines)-1]))
+	if len(line) > maxErrorLength {
+		line = line[:maxErrorLength]
+	}
+	return line
+}
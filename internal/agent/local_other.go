@@ -0,0 +1,17 @@
+//go:build !unix
+
+package agent
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// execCommand runs command through sh. Without process groups, cancelling
+// ctx only kills the shell.
+func execCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.WaitDelay = 10 * time.Second
+	return cmd
+}
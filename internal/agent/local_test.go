@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"qualgent-test-platform/internal/config"
+)
+
+func TestLocalExecutorPrunesOldJobDirs(t *testing.T) {
+	dir := t.TempDir()
+	old, recent := filepath.Join(dir, "job-old"), filepath.Join(dir, "job-recent")
+	for _, jobDir := range []string{old, recent} {
+		if err := os.MkdirAll(jobDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultAgent().Local
+	cfg.WorkDir = dir
+	cfg.Retention = 24 * time.Hour
+	NewLocalExecutor(cfg, "web").prune(context.Background())
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("%s was kept past its retention", old)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("%s was removed: %v", recent, err)
+	}
+}
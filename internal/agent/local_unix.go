@@ -0,0 +1,24 @@
+//go:build unix
+
+package agent
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// execCommand runs command through sh in a process group of its own, so
+// that cancelling ctx kills everything the command started, such as the
+// browsers a test runner launches.
+func execCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Don't wait on output pipes held open by orphaned children
+	cmd.WaitDelay = 10 * time.Second
+	return cmd
+}
@@ -0,0 +1,52 @@
+// Package artifact locates the apps and test bundles that jobs name, which
+// may be URLs or paths under an artifacts directory.
+package artifact
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// IsURL reports whether s is an http or https URL.
+func IsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// IsLocal reports whether path stays under the directory it is resolved
+// against, rather than being absolute or climbing out with "..".
+func IsLocal(path string) bool {
+	return filepath.IsLocal(path)
+}
+
+// Path resolves path against dir, or the working directory if dir is
+// empty, never looking outside it.
+func Path(dir, path string) string {
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(dir, filepath.Clean("/"+path))
+}
+
+// Stat describes the file at a resolved path, refusing anything but a
+// regular file, such as a device or a directory.
+func Stat(path string) (os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	return info, nil
+}
+
+// Open opens the regular file at a resolved path.
+func Open(path string) (*os.File, error) {
+	if _, err := Stat(path); err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
@@ -0,0 +1,517 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the on-disk configuration. Each binary reads only its own
+// section, so one file can be shared by a whole deployment.
+type File struct {
+	Server Server `yaml:"server"`
+	Agent  Agent  `yaml:"agent"`
+}
+
+// Server configures job-server.
+type Server struct {
+	GRPCPort  string          `yaml:"grpc_port" env:"GRPC_PORT"`
+	HTTPPort  string          `yaml:"http_port" env:"HTTP_PORT"`
+	AdminAddr string          `yaml:"admin_addr" env:"ADMIN_ADDR"`
+	Database  Database        `yaml:"database"`
+	Redis     Redis           `yaml:"redis"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Ingest    Ingest          `yaml:"ingest"`
+	Cache     Cache           `yaml:"cache"`
+	Dashboard Dashboard       `yaml:"dashboard"`
+	Health    Health          `yaml:"health"`
+	Log       Log             `yaml:"log"`
+}
+
+// Database selects the job store. Driver "postgres" uses the connection
+// fields; driver "sqlite" uses Path and ignores the rest.
+type Database struct {
+	Driver   string `yaml:"driver" env:"DB_DRIVER"`
+	Path     string `yaml:"path" env:"DB_PATH"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE"`
+}
+
+// ConnString returns the lib/pq connection string.
+func (d Database) ConnString() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode)
+}
+
+type Redis struct {
+	Addr          string        `yaml:"addr" env:"REDIS_ADDR"`
+	CheckInterval time.Duration `yaml:"check_interval" env:"REDIS_CHECK_INTERVAL"`
+}
+
+type SchedulerConfig struct {
+	Interval          time.Duration `yaml:"interval" env:"SCHEDULER_INTERVAL"`
+	BatchSize         int           `yaml:"batch_size" env:"SCHEDULER_BATCH_SIZE"`
+	LockTTL           time.Duration `yaml:"lock_ttl" env:"SCHEDULER_LOCK_TTL"`
+	ReconcileInterval time.Duration `yaml:"reconcile_interval" env:"SCHEDULER_RECONCILE_INTERVAL"`
+}
+
+// Ingest configures the pre-flight checks submitted jobs pass before the
+// scheduler sees them. Apps, Policies and ProbeHosts are only settable in
+// the file.
+type Ingest struct {
+	Workers           int           `yaml:"workers" env:"INGEST_WORKERS"`
+	ReconcileInterval time.Duration `yaml:"reconcile_interval" env:"INGEST_RECONCILE_INTERVAL"`
+	// Directory local app and test paths are resolved against; empty skips
+	// the existence checks for local paths
+	ArtifactsDir string        `yaml:"artifacts_dir" env:"INGEST_ARTIFACTS_DIR"`
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"INGEST_CHECK_TIMEOUT"`
+	// Hosts whose URLs the checks may request, by name or as *.example.com.
+	// URLs on other hosts aren't checked, so that a submitted job can't make
+	// the server fetch internal addresses
+	ProbeHosts []string `yaml:"probe_hosts"`
+	// App version aliases, e.g. "1.4.2": "bs://abc123"
+	Apps map[string]string `yaml:"apps"`
+	// Per-org policies keyed by org_id; "*" applies to orgs without one
+	Policies map[string]OrgPolicy `yaml:"policies"`
+}
+
+type OrgPolicy struct {
+	// Targets the org may submit to; empty allows all
+	AllowedTargets []string `yaml:"allowed_targets"`
+	// Higher priorities are lowered to this; 0 means no cap
+	MaxPriority int `yaml:"max_priority"`
+}
+
+// Policy returns the policy for orgID, falling back to "*".
+func (c Ingest) Policy(orgID string) (OrgPolicy, bool) {
+	if policy, ok := c.Policies[orgID]; ok {
+		return policy, true
+	}
+	policy, ok := c.Policies["*"]
+	return policy, ok
+}
+
+type Cache struct {
+	JobStatusTTL   time.Duration `yaml:"job_status_ttl" env:"CACHE_JOB_STATUS_TTL"`
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"CACHE_IDEMPOTENCY_TTL"`
+	HeartbeatTTL   time.Duration `yaml:"heartbeat_ttl" env:"AGENT_HEARTBEAT_TTL"`
+}
+
+type Dashboard struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"DASHBOARD_REFRESH_INTERVAL"`
+}
+
+type Health struct {
+	CheckInterval time.Duration `yaml:"check_interval" env:"HEALTH_CHECK_INTERVAL"`
+}
+
+type Log struct {
+	Level            string `yaml:"level" env:"LOG_LEVEL"`
+	Format           string `yaml:"format" env:"LOG_FORMAT"`
+	SampleInitial    int    `yaml:"sample_initial" env:"LOG_SAMPLE_INITIAL"`
+	SampleThereafter int    `yaml:"sample_thereafter" env:"LOG_SAMPLE_THEREAFTER"`
+}
+
+// Agent configures appwright-agent.
+type Agent struct {
+	Server            string        `yaml:"server" env:"AGENT_SERVER"`
+	Hostname          string        `yaml:"hostname" env:"AGENT_HOSTNAME"`
+	Name              string        `yaml:"name" env:"AGENT_NAME"`
+	MetricsAddr       string        `yaml:"metrics_addr" env:"AGENT_METRICS_ADDR"`
+	ReconnectInterval time.Duration `yaml:"reconnect_interval" env:"AGENT_RECONNECT_INTERVAL"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" env:"AGENT_HEARTBEAT_INTERVAL"`
+	// Targets lists the targets to run jobs for, separated by commas, each
+	// with its own executor.
+	Targets string `yaml:"targets" env:"AGENT_TARGETS"`
+	// Slots is how many job groups the agent runs at once; shutdown waits
+	// up to ShutdownTimeout for running groups to finish.
+	Slots           int           `yaml:"slots" env:"AGENT_SLOTS"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"AGENT_SHUTDOWN_TIMEOUT"`
+	// Labels advertise the agent's capabilities, such as os_version or
+	// device, for matching against job label constraints.
+	Labels       map[string]string `yaml:"labels"`
+	BrowserStack BrowserStack      `yaml:"browserstack"`
+	Local        Local             `yaml:"local"`
+	Log          Log               `yaml:"log"`
+}
+
+type BrowserStack struct {
+	BaseURL        string        `yaml:"base_url" env:"BROWSERSTACK_BASE_URL"`
+	Username       string        `yaml:"username" env:"BROWSERSTACK_USERNAME"`
+	AccessKey      string        `yaml:"access_key" env:"BROWSERSTACK_ACCESS_KEY" secret:"true"`
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"BROWSERSTACK_REQUEST_TIMEOUT"`
+	PollInterval   time.Duration `yaml:"poll_interval" env:"BROWSERSTACK_POLL_INTERVAL"`
+	SessionTimeout time.Duration `yaml:"session_timeout" env:"BROWSERSTACK_SESSION_TIMEOUT"`
+	// Directory local app and test-suite paths are resolved against and
+	// confined to; empty means the agent's working directory
+	ArtifactsDir  string        `yaml:"artifacts_dir" env:"BROWSERSTACK_ARTIFACTS_DIR"`
+	UploadTimeout time.Duration `yaml:"upload_timeout" env:"BROWSERSTACK_UPLOAD_TIMEOUT"`
+}
+
+// Local configures the executor that runs web, emulator and device jobs as
+// a local command, each target with its own. The command runs through sh
+// in a working directory of its own per job.
+type Local struct {
+	Command         string        `yaml:"command" env:"LOCAL_COMMAND"`
+	EmulatorCommand string        `yaml:"emulator_command" env:"LOCAL_EMULATOR_COMMAND"`
+	DeviceCommand   string        `yaml:"device_command" env:"LOCAL_DEVICE_COMMAND"`
+	WorkDir         string        `yaml:"work_dir" env:"LOCAL_WORK_DIR"`
+	Timeout         time.Duration `yaml:"timeout" env:"LOCAL_TIMEOUT"`
+	// Retention is how long a job's working directory, with its logs and
+	// video, is kept; 0 keeps them all.
+	Retention time.Duration `yaml:"retention" env:"LOCAL_RETENTION"`
+}
+
+// CommandFor returns the command run for a target's jobs: Command for web.
+func (l Local) CommandFor(target string) string {
+	switch target {
+	case "emulator":
+		return l.EmulatorCommand
+	case "device":
+		return l.DeviceCommand
+	}
+	return l.Command
+}
+
+// DefaultServer returns the built-in job-server settings.
+func DefaultServer() Server {
+	return Server{
+		GRPCPort:  "8080",
+		HTTPPort:  "8081",
+		AdminAddr: "127.0.0.1:8082",
+		Database: Database{
+			Driver:   "postgres",
+			Path:     "qg_jobs.db",
+			Host:     "localhost",
+			Port:     "5432",
+			User:     "user",
+			Password: "password",
+			Name:     "qg_jobs",
+			SSLMode:  "disable",
+		},
+		Redis: Redis{
+			Addr:          "localhost:6379",
+			CheckInterval: 5 * time.Second,
+		},
+		Scheduler: SchedulerConfig{
+			Interval:          5 * time.Second,
+			BatchSize:         10,
+			LockTTL:           60 * time.Second,
+			ReconcileInterval: time.Minute,
+		},
+		Ingest: Ingest{
+			Workers:           2,
+			ReconcileInterval: time.Minute,
+			CheckTimeout:      10 * time.Second,
+		},
+		Cache: Cache{
+			JobStatusTTL:   5 * time.Minute,
+			IdempotencyTTL: 24 * time.Hour,
+			HeartbeatTTL:   2 * time.Minute,
+		},
+		Dashboard: Dashboard{RefreshInterval: 5 * time.Second},
+		Health:    Health{CheckInterval: 5 * time.Second},
+		Log:       Log{Level: "info", Format: "json", SampleThereafter: 100},
+	}
+}
+
+// DefaultAgent returns the built-in appwright-agent settings.
+func DefaultAgent() Agent {
+	return Agent{
+		Server:            "localhost:8080",
+		MetricsAddr:       ":9091",
+		ReconnectInterval: 5 * time.Second,
+		HeartbeatInterval: 30 * time.Second,
+		Targets:           "browserstack",
+		Slots:             1,
+		ShutdownTimeout:   10 * time.Minute,
+		BrowserStack: BrowserStack{
+			BaseURL:        "https://api-cloud.browserstack.com/app-automate/v2",
+			RequestTimeout: 30 * time.Second,
+			PollInterval:   10 * time.Second,
+			SessionTimeout: 10 * time.Minute,
+			UploadTimeout:  5 * time.Minute,
+		},
+		Local: Local{
+			Command:   `npx playwright test "$QG_TEST_PATH"`,
+			WorkDir:   filepath.Join(os.TempDir(), "qgjob-runs"),
+			Timeout:   30 * time.Minute,
+			Retention: 24 * time.Hour,
+		},
+		Log: Log{Level: "info", Format: "json", SampleThereafter: 100},
+	}
+}
+
+// LoadServer builds job-server settings from defaults, the optional YAML
+// file at path, and then environment overrides.
+func LoadServer(path string) (Server, error) {
+	file := File{Server: DefaultServer()}
+	if err := load(path, &file, &file.Server); err != nil {
+		return Server{}, err
+	}
+	return file.Server, file.Server.Validate()
+}
+
+// LoadAgent builds appwright-agent settings the same way as LoadServer.
+func LoadAgent(path string) (Agent, error) {
+	file := File{Agent: DefaultAgent()}
+	if err := load(path, &file, &file.Agent); err != nil {
+		return Agent{}, err
+	}
+	return file.Agent, nil
+}
+
+func load(path string, file *File, section interface{}) error {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(file); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+	return applyEnv(reflect.ValueOf(section).Elem())
+}
+
+// applyEnv overwrites fields tagged `env:"NAME"` with non-empty
+// environment variables.
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Duration(0)) {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := t.Field(i).Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		switch {
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			field.SetInt(int64(d))
+		case field.Kind() == reflect.String:
+			field.SetString(value)
+		case field.Kind() == reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			field.SetInt(int64(n))
+		case field.Kind() == reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			field.SetBool(b)
+		}
+	}
+	return nil
+}
+
+// Validate reports every invalid server setting at once.
+func (c Server) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.GRPCPort), "grpc_port %q is not a valid port", c.GRPCPort)
+	check(validPort(c.HTTPPort), "http_port %q is not a valid port", c.HTTPPort)
+	check(c.GRPCPort != c.HTTPPort, "grpc_port and http_port must differ")
+	if _, adminPort, err := net.SplitHostPort(c.AdminAddr); err != nil || !validPort(adminPort) {
+		check(false, "admin_addr %q must be host:port", c.AdminAddr)
+	} else {
+		check(adminPort != c.GRPCPort && adminPort != c.HTTPPort, "admin_addr port must differ from grpc_port and http_port")
+	}
+	switch c.Database.Driver {
+	case "postgres":
+		check(c.Database.Host != "", "database.host is required")
+		check(validPort(c.Database.Port), "database.port %q is not a valid port", c.Database.Port)
+		check(c.Database.Name != "", "database.name is required")
+	case "sqlite":
+		check(c.Database.Path != "", "database.path is required for the sqlite driver")
+	default:
+		check(false, "database.driver %q must be postgres or sqlite", c.Database.Driver)
+	}
+	check(c.Redis.Addr != "", "redis.addr is required")
+	check(c.Redis.CheckInterval > 0, "redis.check_interval must be positive")
+	check(c.Scheduler.Interval > 0, "scheduler.interval must be positive")
+	check(c.Scheduler.BatchSize > 0, "scheduler.batch_size must be positive")
+	check(c.Scheduler.LockTTL > c.Scheduler.Interval, "scheduler.lock_ttl must be longer than scheduler.interval")
+	check(c.Scheduler.ReconcileInterval > 0, "scheduler.reconcile_interval must be positive")
+	check(c.Ingest.Workers > 0, "ingest.workers must be positive")
+	check(c.Ingest.ReconcileInterval > 0, "ingest.reconcile_interval must be positive")
+	check(c.Ingest.CheckTimeout > 0, "ingest.check_timeout must be positive")
+	for org, policy := range c.Ingest.Policies {
+		for _, target := range policy.AllowedTargets {
+			check(validTarget(target), "ingest.policies.%s: target %q must be emulator, device, browserstack or web", org, target)
+		}
+		check(policy.MaxPriority >= 0, "ingest.policies.%s: max_priority must not be negative", org)
+	}
+	check(c.Cache.JobStatusTTL > 0, "cache.job_status_ttl must be positive")
+	check(c.Cache.IdempotencyTTL > 0, "cache.idempotency_ttl must be positive")
+	check(c.Cache.HeartbeatTTL > 0, "cache.heartbeat_ttl must be positive")
+	check(c.Dashboard.RefreshInterval >= 0, "dashboard.refresh_interval must not be negative")
+	check(c.Health.CheckInterval > 0, "health.check_interval must be positive")
+	errs = append(errs, c.Log.validate()...)
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid agent setting at once.
+func (c Agent) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server != "", "server is required")
+	check(c.ReconnectInterval > 0, "reconnect_interval must be positive")
+	check(c.HeartbeatInterval > 0, "heartbeat_interval must be positive")
+	check(len(c.TargetList()) > 0, "targets must list at least one target")
+	for _, target := range c.TargetList() {
+		check(validTargets[target], "targets: unknown target %q", target)
+	}
+	check(c.Slots > 0, "slots must be positive")
+	check(c.ShutdownTimeout >= 0, "shutdown_timeout must not be negative")
+	for key := range c.Labels {
+		check(key != "", "labels must not have an empty key")
+	}
+	if slices.Contains(c.TargetList(), "browserstack") {
+		check(c.BrowserStack.BaseURL != "", "browserstack.base_url (BROWSERSTACK_BASE_URL) is required")
+		check(c.BrowserStack.Username != "", "browserstack.username (BROWSERSTACK_USERNAME) is required")
+		check(c.BrowserStack.AccessKey != "", "browserstack.access_key (BROWSERSTACK_ACCESS_KEY) is required")
+		check(c.BrowserStack.RequestTimeout > 0, "browserstack.request_timeout must be positive")
+		check(c.BrowserStack.PollInterval > 0, "browserstack.poll_interval must be positive")
+		check(c.BrowserStack.UploadTimeout > 0, "browserstack.upload_timeout must be positive")
+		check(c.BrowserStack.SessionTimeout >= c.BrowserStack.PollInterval, "browserstack.session_timeout must be at least browserstack.poll_interval")
+	}
+	local := false
+	for _, target := range c.TargetList() {
+		if setting, ok := localCommandSettings[target]; ok {
+			check(c.Local.CommandFor(target) != "", "%s is required for the %s target", setting, target)
+			local = true
+		}
+	}
+	if local {
+		check(c.Local.WorkDir != "", "local.work_dir (LOCAL_WORK_DIR) is required")
+		check(c.Local.Timeout > 0, "local.timeout must be positive")
+		check(c.Local.Retention >= 0, "local.retention must not be negative")
+	}
+	errs = append(errs, c.Log.validate()...)
+
+	return errors.Join(errs...)
+}
+
+var validTargets = map[string]bool{"emulator": true, "device": true, "browserstack": true, "web": true}
+
+// localCommandSettings names the setting holding each local target's command.
+var localCommandSettings = map[string]string{
+	"web":      "local.command (LOCAL_COMMAND)",
+	"emulator": "local.emulator_command (LOCAL_EMULATOR_COMMAND)",
+	"device":   "local.device_command (LOCAL_DEVICE_COMMAND)",
+}
+
+// TargetList returns the agent's targets, trimmed and without empties.
+func (c Agent) TargetList() []string {
+	var targets []string
+	for _, target := range strings.Split(c.Targets, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func (l Log) validate() []error {
+	var errs []error
+	switch strings.ToLower(l.Level) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log.level %q must be debug, info, warn or error", l.Level))
+	}
+	switch strings.ToLower(l.Format) {
+	case "", "json", "text":
+	default:
+		errs = append(errs, fmt.Errorf("log.format %q must be json or text", l.Format))
+	}
+	if l.SampleInitial < 0 || l.SampleThereafter < 0 {
+		errs = append(errs, fmt.Errorf("log sampling values must not be negative"))
+	}
+	return errs
+}
+
+func validTarget(target string) bool {
+	switch target {
+	case "emulator", "device", "browserstack", "web":
+		return true
+	}
+	return false
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+// Print writes the effective settings as YAML under their section key,
+// with secrets redacted.
+func Print(w io.Writer, section string, cfg interface{}) error {
+	redacted := reflect.New(reflect.TypeOf(cfg)).Elem()
+	redacted.Set(reflect.ValueOf(cfg))
+	redact(redacted)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	defer enc.Close()
+	return enc.Encode(map[string]interface{}{section: redacted.Interface()})
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			redact(field)
+			continue
+		}
+		if t.Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString("REDACTED")
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package dashboard
+
+import (
+	"embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"qualgent-test-platform/internal/store"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// listLimit caps how many jobs and groups a single page renders.
+const listLimit = 100
+
+// Dashboard is a read-only web UI over the job store.
+type Dashboard struct {
+	jobStore        store.JobStore
+	agentStore      store.AgentStore
+	refreshInterval time.Duration
+	pages           map[string]*template.Template
+	mux             *http.ServeMux
+}
+
+type pageData struct {
+	Title   string
+	Refresh int
+	Jobs    []*store.Job
+	Job     *store.Job
+	Groups  []*store.JobGroup
+	Agents  []*store.Agent
+	Now     time.Time
+}
+
+func NewDashboard(jobStore store.JobStore, agentStore store.AgentStore, refreshInterval time.Duration) (*Dashboard, error) {
+	d := &Dashboard{
+		jobStore:        jobStore,
+		agentStore:      agentStore,
+		refreshInterval: refreshInterval,
+		pages:           make(map[string]*template.Template),
+		mux:             http.NewServeMux(),
+	}
+
+	funcs := template.FuncMap{
+		"deref":     deref,
+		"fmtTime":   fmtTime,
+		"statusCSS": statusCSS,
+	}
+	for _, page := range []string{"jobs", "job", "groups", "agents"} {
+		tmpl, err := template.New("layout.html").Funcs(funcs).ParseFS(templateFS, "templates/layout.html", "templates/"+page+".html")
+		if err != nil {
+			return nil, err
+		}
+		d.pages[page] = tmpl
+	}
+
+	d.mux.HandleFunc("GET /{$}", d.handleJobs)
+	d.mux.HandleFunc("GET /jobs/{id}", d.handleJob)
+	d.mux.HandleFunc("GET /groups", d.handleGroups)
+	d.mux.HandleFunc("GET /agents", d.handleAgents)
+
+	return d, nil
+}
+
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mux.ServeHTTP(w, r)
+}
+
+func (d *Dashboard) handleJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := d.jobStore.ListJobs(r.Context(), listLimit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Dashboard failed to list jobs", "error", err)
+		http.Error(w, "failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+	d.render(w, "jobs", &pageData{Title: "Jobs", Jobs: jobs})
+}
+
+func (d *Dashboard) handleJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := d.jobStore.GetJob(r.Context(), jobID)
+	if err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	d.render(w, "job", &pageData{Title: "Job " + job.ID.String(), Job: job})
+}
+
+func (d *Dashboard) handleGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := d.jobStore.ListJobGroups(r.Context(), listLimit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Dashboard failed to list job groups", "error", err)
+		http.Error(w, "failed to list job groups", http.StatusInternalServerError)
+		return
+	}
+	d.render(w, "groups", &pageData{Title: "Job Groups", Groups: groups})
+}
+
+func (d *Dashboard) handleAgents(w http.ResponseWriter, r *http.Request) {
+	agents, err := d.agentStore.ListAgents(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Dashboard failed to list agents", "error", err)
+		http.Error(w, "failed to list agents", http.StatusInternalServerError)
+		return
+	}
+	d.render(w, "agents", &pageData{Title: "Agents", Agents: agents})
+}
+
+func (d *Dashboard) render(w http.ResponseWriter, page string, data *pageData) {
+	data.Refresh = int(d.refreshInterval.Seconds())
+	data.Now = time.Now()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := d.pages[page].Execute(w, data); err != nil {
+		slog.Error("Dashboard failed to render page", "page", page, "error", err)
+	}
+}
+
+func deref(v interface{}) interface{} {
+	switch p := v.(type) {
+	case *string:
+		if p != nil {
+			return *p
+		}
+	case *int32:
+		if p != nil {
+			return *p
+		}
+	case *uuid.UUID:
+		if p != nil {
+			return p.String()
+		}
+	case *time.Time:
+		if p != nil {
+			return fmtTime(*p)
+		}
+	}
+	return ""
+}
+
+func fmtTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+func statusCSS(status string) string {
+	switch status {
+	case "COMPLETED", "IDLE":
+		return "ok"
+	case "FAILED", "PARTIAL_FAILURE":
+		return "bad"
+	case "RUNNING", "ASSIGNED", "BUSY":
+		return "busy"
+	default:
+		return "pending"
+	}
+}
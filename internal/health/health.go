@@ -0,0 +1,224 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Check reports whether a dependency is usable.
+type Check func(ctx context.Context) error
+
+// Checker keeps the gRPC health service and the HTTP readiness endpoint in
+// sync with the result of periodic dependency checks.
+type Checker struct {
+	server   *health.Server
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.RWMutex
+	names    []string
+	checks   map[string]Check
+	optional map[string]bool
+	errors   map[string]string
+
+	draining atomic.Bool
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewChecker creates a checker reporting on the overall server ("") and the
+// given fully-qualified gRPC service names. Everything starts NOT_SERVING
+// until the first round of checks passes.
+func NewChecker(interval time.Duration, services ...string) *Checker {
+	c := &Checker{
+		server:   health.NewServer(),
+		services: append([]string{""}, services...),
+		interval: interval,
+		timeout:  2 * time.Second,
+		checks:   make(map[string]Check),
+		optional: make(map[string]bool),
+		errors:   make(map[string]string),
+		stopChan: make(chan struct{}),
+	}
+	c.setServing(false)
+	return c
+}
+
+// Server returns the gRPC health service to register.
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// AddCheck registers a named dependency check. Must be called before Start.
+func (c *Checker) AddCheck(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names = append(c.names, name)
+	c.checks[name] = check
+}
+
+// AddOptionalCheck registers a dependency the server can run without. Its
+// failures show up as "degraded" on /readyz but keep the server serving.
+func (c *Checker) AddOptionalCheck(name string, check Check) {
+	c.AddCheck(name, check)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.optional[name] = true
+}
+
+func (c *Checker) Start(ctx context.Context) {
+	c.runChecks(ctx)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stopChan:
+				return
+			case <-ticker.C:
+				c.runChecks(ctx)
+			}
+		}
+	}()
+}
+
+// Drain marks the server NOT_SERVING for the rest of its life so load
+// balancers stop routing to it while in-flight work finishes.
+func (c *Checker) Drain() {
+	if c.draining.Swap(true) {
+		return
+	}
+	close(c.stopChan)
+	c.wg.Wait()
+	c.server.Shutdown()
+	slog.Info("Health status set to NOT_SERVING for drain")
+}
+
+func (c *Checker) runChecks(ctx context.Context) {
+	c.mu.RLock()
+	names := append([]string(nil), c.names...)
+	c.mu.RUnlock()
+
+	errs := make(map[string]string)
+	for _, name := range names {
+		checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := c.checks[name](checkCtx)
+		cancel()
+		if err != nil {
+			errs[name] = err.Error()
+		}
+	}
+
+	c.mu.Lock()
+	for name := range c.errors {
+		if _, failing := errs[name]; !failing {
+			slog.Info("Dependency recovered", "dependency", name)
+		}
+	}
+	for name, msg := range errs {
+		if _, failing := c.errors[name]; !failing {
+			slog.Warn("Dependency check failed", "dependency", name, "error", msg)
+		}
+	}
+	c.errors = errs
+	ready := c.readyLocked()
+	c.mu.Unlock()
+
+	if !c.draining.Load() {
+		c.setServing(ready)
+	}
+}
+
+// readyLocked reports whether every required check passed. Callers hold c.mu.
+func (c *Checker) readyLocked() bool {
+	for name := range c.errors {
+		if !c.optional[name] {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Checker) setServing(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
+
+type readiness struct {
+	Status   string            `json:"status"`
+	Draining bool              `json:"draining,omitempty"`
+	Checks   map[string]string `json:"checks"`
+}
+
+// Ready reports whether the server should receive traffic.
+func (c *Checker) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.draining.Load() && c.readyLocked()
+}
+
+// LivenessHandler serves /healthz: the process is up and serving HTTP.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+}
+
+// ReadinessHandler serves /readyz: 200 when every required dependency check
+// passed and the server is not draining, 503 otherwise.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.RLock()
+		resp := readiness{
+			Status:   "ok",
+			Draining: c.draining.Load(),
+			Checks:   make(map[string]string, len(c.names)),
+		}
+		for _, name := range c.names {
+			if msg, failing := c.errors[name]; failing {
+				resp.Checks[name] = msg
+				if c.optional[name] {
+					if resp.Status == "ok" {
+						resp.Status = "degraded"
+					}
+				} else {
+					resp.Status = "unavailable"
+				}
+			} else {
+				resp.Checks[name] = "ok"
+			}
+		}
+		c.mu.RUnlock()
+
+		if resp.Draining {
+			resp.Status = "draining"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if resp.Status != "ok" && resp.Status != "degraded" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+}
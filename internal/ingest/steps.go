@@ -0,0 +1,253 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"qualgent-test-platform/internal/artifact"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/store"
+)
+
+// DefaultSteps returns the built-in pre-flight steps in the order they run.
+func DefaultSteps(cfg config.Ingest) []Step {
+	prober := newProber(cfg)
+	return []Step{
+		&OrgPolicy{config: cfg},
+		&ResolveApp{apps: cfg.Apps, artifactsDir: cfg.ArtifactsDir},
+		&TestBundle{artifactsDir: cfg.ArtifactsDir, prober: prober},
+		&WebReachable{prober: prober},
+	}
+}
+
+// OrgPolicy rejects targets an org may not use and caps its priority.
+type OrgPolicy struct {
+	config config.Ingest
+}
+
+func (s *OrgPolicy) Name() string { return "org_policy" }
+
+func (s *OrgPolicy) Check(ctx context.Context, job *store.Job) error {
+	policy, ok := s.config.Policy(job.OrgID)
+	if !ok {
+		return nil
+	}
+
+	if len(policy.AllowedTargets) > 0 && !contains(policy.AllowedTargets, job.Target) {
+		return Reject("org %s may not submit to target %s", job.OrgID, job.Target)
+	}
+	if policy.MaxPriority > 0 && int(job.Priority) > policy.MaxPriority {
+		slog.InfoContext(ctx, "Capping job priority", "requested", job.Priority, "max_priority", policy.MaxPriority)
+		job.Priority = int32(policy.MaxPriority)
+	}
+	return nil
+}
+
+// ResolveApp turns app_version_id aliases into the app they name and checks
+// that local app files exist. BrowserStack IDs (bs://) and URLs are taken
+// as they are.
+type ResolveApp struct {
+	apps         map[string]string
+	artifactsDir string
+}
+
+func (s *ResolveApp) Name() string { return "resolve_app" }
+
+func (s *ResolveApp) Check(ctx context.Context, job *store.Job) error {
+	if job.Target == "web" {
+		return nil
+	}
+	if job.AppVersionID == "" {
+		return Reject("app_version_id is required for target %s", job.Target)
+	}
+
+	if app, ok := s.apps[job.AppVersionID]; ok {
+		slog.DebugContext(ctx, "Resolved app version", "app_version_id", job.AppVersionID, "app", app)
+		job.AppVersionID = app
+	}
+
+	if strings.HasPrefix(job.AppVersionID, "bs://") || artifact.IsURL(job.AppVersionID) {
+		return nil
+	}
+	// The agent only uploads files under its artifacts directory
+	if !artifact.IsLocal(job.AppVersionID) {
+		return Reject("app %s must be a relative path under the artifacts directory", job.AppVersionID)
+	}
+	if s.artifactsDir == "" {
+		return nil
+	}
+	if err := checkArtifact(s.artifactsDir, job.AppVersionID); err != nil {
+		return Reject("app %s not found: %v", job.AppVersionID, err)
+	}
+	return nil
+}
+
+// TestBundle checks that the test bundle exists, either as a file under
+// the artifacts directory or at a URL on an allowed host. Only a definite
+// client error such as 404 rejects the job.
+type TestBundle struct {
+	artifactsDir string
+	prober       *prober
+}
+
+func (s *TestBundle) Name() string { return "test_bundle" }
+
+func (s *TestBundle) Check(ctx context.Context, job *store.Job) error {
+	if artifact.IsURL(job.TestPath) {
+		if !s.prober.allows(ctx, job.TestPath) {
+			return nil
+		}
+		code, err := s.prober.probe(ctx, job.TestPath)
+		switch {
+		case err != nil:
+			return fmt.Errorf("failed to check test bundle %s: %w", job.TestPath, err)
+		case transientStatus(code):
+			return fmt.Errorf("test bundle %s answered status %d", job.TestPath, code)
+		case code >= http.StatusBadRequest:
+			return Reject("test bundle %s is not available: status %d", job.TestPath, code)
+		}
+		return nil
+	}
+	// Espresso test suites are uploaded like apps
+	if job.TestType != nil && *job.TestType == "ESPRESSO" && !artifact.IsLocal(job.TestPath) {
+		return Reject("test suite %s must be a relative path under the artifacts directory", job.TestPath)
+	}
+	if s.artifactsDir == "" {
+		return nil
+	}
+	if err := checkArtifact(s.artifactsDir, job.TestPath); err != nil {
+		return Reject("test bundle %s not found: %v", job.TestPath, err)
+	}
+	return nil
+}
+
+// WebReachable checks that a web job's web_app_url answers, if it is on an
+// allowed host. Client errors such as 401 or 404 still count as reachable,
+// since the app may sit behind a login the test handles. An app that can't
+// be reached, or answers 5xx, may be back shortly, so the job is retried
+// rather than rejected.
+type WebReachable struct {
+	prober *prober
+}
+
+func (s *WebReachable) Name() string { return "web_reachable" }
+
+func (s *WebReachable) Check(ctx context.Context, job *store.Job) error {
+	if job.Target != "web" {
+		return nil
+	}
+	if job.WebAppURL == nil || !artifact.IsURL(*job.WebAppURL) {
+		return Reject("web_app_url must be an http or https URL")
+	}
+	if !s.prober.allows(ctx, *job.WebAppURL) {
+		return nil
+	}
+	code, err := s.prober.probe(ctx, *job.WebAppURL)
+	if err != nil {
+		return fmt.Errorf("failed to reach web_app_url %s: %w", *job.WebAppURL, err)
+	}
+	if code >= http.StatusInternalServerError {
+		return fmt.Errorf("web_app_url %s answered status %d", *job.WebAppURL, code)
+	}
+	return nil
+}
+
+// prober requests user-supplied URLs, but only on the hosts in
+// ingest.probe_hosts, and follows redirects only to those hosts.
+type prober struct {
+	client *http.Client
+	hosts  []string
+}
+
+func newProber(cfg config.Ingest) *prober {
+	p := &prober{hosts: cfg.ProbeHosts}
+	p.client = &http.Client{
+		Timeout: cfg.CheckTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// A redirect elsewhere is judged on the redirect itself
+			if len(via) >= 10 || !p.allowedHost(req.URL.Hostname()) {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+	return p
+}
+
+// allows reports whether target may be requested.
+func (p *prober) allows(ctx context.Context, target string) bool {
+	u, err := url.Parse(target)
+	if err != nil || !p.allowedHost(u.Hostname()) {
+		slog.DebugContext(ctx, "Not checking URL on a host missing from ingest.probe_hosts", "url", target)
+		return false
+	}
+	return true
+}
+
+func (p *prober) allowedHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range p.hosts {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// probe sends a HEAD request, falling back to GET for servers that don't
+// allow HEAD, and returns the status. Errors are timeouts and connection
+// failures, which may clear up.
+func (p *prober) probe(ctx context.Context, target string) (int, error) {
+	code, err := request(ctx, p.client, http.MethodHead, target)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = request(ctx, p.client, http.MethodGet, target)
+	}
+	return code, err
+}
+
+// transientStatus reports whether a status may change if asked again.
+func transientStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+func request(ctx context.Context, client *http.Client, method, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// checkArtifact checks that path exists inside dir, never looking outside
+// it. Test bundles may be directories.
+func checkArtifact(dir, path string) error {
+	_, err := artifact.Stat(artifact.Path(dir, path))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no such file in %s", dir)
+	}
+	return err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+// Package ingest validates and enriches submitted jobs before the scheduler
+// sees them. Workers pop job IDs off the ingestion queue and run each job
+// through a list of pre-flight steps; a job that passes becomes PENDING, a
+// job a step rejects is failed with the step's reason.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/metrics"
+	"qualgent-test-platform/internal/store"
+	"qualgent-test-platform/internal/tracing"
+)
+
+const (
+	// popTimeout bounds each blocking pop so workers notice Stop
+	popTimeout = 5 * time.Second
+	// retryDelay spaces out retries after store errors
+	retryDelay = time.Second
+	// reconcileBatch caps the SUBMITTED jobs read per reconciliation
+	reconcileBatch = 500
+)
+
+// Step is one pre-flight check. Check may enrich the job (AppVersionID,
+// TestPath or Priority); the changes are saved when the job is accepted.
+// It returns a Rejection for an invalid job and any other error for a
+// transient failure, which retries the job later.
+type Step interface {
+	Name() string
+	Check(ctx context.Context, job *store.Job) error
+}
+
+// Rejection is returned by a Step when the job can never run.
+type Rejection struct {
+	Reason string
+}
+
+func (r *Rejection) Error() string {
+	return r.Reason
+}
+
+// Reject returns a Rejection with a formatted reason.
+func Reject(format string, args ...interface{}) error {
+	return &Rejection{Reason: fmt.Sprintf(format, args...)}
+}
+
+type Worker struct {
+	jobStore   store.JobStore
+	queueStore store.QueueStore
+	cacheStore store.CacheStore
+	steps      []Step
+	config     config.Ingest
+	cache      config.Cache
+	stopChan   chan struct{}
+	wg         sync.WaitGroup
+}
+
+func NewWorker(jobStore store.JobStore, queueStore store.QueueStore, cacheStore store.CacheStore, steps []Step, cfg config.Ingest, cache config.Cache) *Worker {
+	return &Worker{
+		jobStore:   jobStore,
+		queueStore: queueStore,
+		cacheStore: cacheStore,
+		steps:      steps,
+		config:     cfg,
+		cache:      cache,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) {
+	// Stop cancels in-flight pops and checks rather than waiting them out
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-w.stopChan
+		cancel()
+	}()
+
+	for i := 0; i < w.config.Workers; i++ {
+		w.wg.Add(1)
+		go w.consume(ctx)
+	}
+	w.wg.Add(1)
+	go w.reconcileLoop(ctx)
+	slog.Info("Ingestion started", "workers", w.config.Workers, "steps", len(w.steps))
+}
+
+func (w *Worker) Stop() {
+	close(w.stopChan)
+	w.wg.Wait()
+	slog.Info("Ingestion stopped")
+}
+
+func (w *Worker) consume(ctx context.Context) {
+	defer w.wg.Done()
+
+	for ctx.Err() == nil {
+		jobID, err := w.queueStore.PopFromIngestionQueue(ctx, popTimeout)
+		if err != nil {
+			if !errors.Is(err, store.ErrQueueEmpty) && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to pop from ingestion queue", "error", err)
+				w.sleep(ctx, retryDelay)
+			}
+			continue
+		}
+
+		if err := w.ingest(ctx, jobID); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "Ingestion failed, retrying job", logging.KeyJobID, jobID.String(), "error", err)
+			w.sleep(ctx, retryDelay)
+			if err := w.queueStore.PushToIngestionQueue(context.WithoutCancel(ctx), jobID); err != nil {
+				// Reconciliation picks the job up again
+				slog.ErrorContext(ctx, "Failed to push job back to ingestion queue", logging.KeyJobID, jobID.String(), "error", err)
+			}
+		}
+	}
+}
+
+// ingest runs a job through the steps. It returns an error only when the
+// job should be retried.
+func (w *Worker) ingest(ctx context.Context, jobID uuid.UUID) error {
+	job, err := w.jobStore.GetJob(ctx, jobID)
+	if errors.Is(err, store.ErrNotFound) {
+		slog.WarnContext(ctx, "Dropping unknown job from ingestion queue", logging.KeyJobID, jobID.String())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if job.Status != "SUBMITTED" {
+		// Already ingested; a duplicate queue entry
+		return nil
+	}
+
+	ctx = logging.WithJob(ctx, job.ID.String(), job.OrgID)
+	ctx, span := tracing.Tracer("ingest").Start(tracing.JobContext(ctx, job.TraceID, job.SpanID), "Ingest.preflight")
+	span.SetAttributes(attribute.String("job.id", job.ID.String()), attribute.String("job.target", job.Target))
+	defer span.End()
+
+	for _, step := range w.steps {
+		err := step.Check(ctx, job)
+		if err == nil {
+			continue
+		}
+
+		var rejection *Rejection
+		if !errors.As(err, &rejection) {
+			span.RecordError(err)
+			return fmt.Errorf("%s: %w", step.Name(), err)
+		}
+		span.SetStatus(codes.Error, rejection.Reason)
+		return w.reject(ctx, job, step.Name(), rejection.Reason)
+	}
+
+	if err := w.jobStore.AcceptJob(ctx, job); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			// Another worker or an operator got there first
+			return nil
+		}
+		return err
+	}
+	if err := w.cacheStore.SetJobStatus(ctx, job.ID, job.Status, w.cache.JobStatusTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to update job status in cache", "error", err)
+	}
+	metrics.IngestJobsAccepted.WithLabelValues(job.Target).Inc()
+	slog.InfoContext(ctx, "Accepted job", "app_version_id", job.AppVersionID, "priority", job.Priority)
+	return nil
+}
+
+func (w *Worker) reject(ctx context.Context, job *store.Job, step, reason string) error {
+	message := fmt.Sprintf("rejected by %s: %s", step, reason)
+	result := &store.JobResult{Status: "FAILED", ErrorMessage: &message}
+	if err := w.jobStore.UpdateJobResult(ctx, job.ID, result); err != nil {
+		return err
+	}
+	if err := w.cacheStore.SetJobStatus(ctx, job.ID, result.Status, w.cache.JobStatusTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to update job status in cache", "error", err)
+	}
+	metrics.IngestJobsRejected.WithLabelValues(job.Target, step).Inc()
+	slog.WarnContext(ctx, "Rejected job", "step", step, "reason", reason)
+	return nil
+}
+
+func (w *Worker) reconcileLoop(ctx context.Context) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.config.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.reconcile(ctx); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to reconcile ingestion queue", "error", err)
+			}
+		}
+	}
+}
+
+// reconcile pushes SUBMITTED jobs missing from the ingestion queue, e.g.
+// because SubmitJob's push failed or Redis lost data. Jobs younger than the
+// interval are left alone so a push or check still in flight isn't
+// duplicated; a duplicate is harmless anyway, since only SUBMITTED jobs are
+// ingested.
+func (w *Worker) reconcile(ctx context.Context) error {
+	jobs, err := w.jobStore.GetSubmittedJobs(ctx, reconcileBatch)
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ids, err := w.queueStore.ListIngestionQueue(ctx)
+	if err != nil {
+		return err
+	}
+	queued := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		queued[id] = true
+	}
+
+	cutoff := time.Now().Add(-w.config.ReconcileInterval)
+	for _, job := range jobs {
+		if queued[job.ID] || job.UpdatedAt.After(cutoff) {
+			continue
+		}
+		if err := w.queueStore.PushToIngestionQueue(ctx, job.ID); err != nil {
+			return fmt.Errorf("failed to push job %s to ingestion queue: %w", job.ID, err)
+		}
+		metrics.IngestJobsReconciled.Inc()
+		slog.WarnContext(logging.WithJob(ctx, job.ID.String(), job.OrgID), "Requeued job missing from ingestion queue")
+	}
+	return nil
+}
+
+func (w *Worker) sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
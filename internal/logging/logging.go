@@ -0,0 +1,239 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Correlation keys attached to log lines when they are known.
+const (
+	KeyJobID   = "job_id"
+	KeyOrgID   = "org_id"
+	KeyAgentID = "agent_id"
+	KeyGroupID = "group_id"
+	KeyTraceID = "trace_id"
+)
+
+// Options controls the process-wide logger.
+type Options struct {
+	// Level is the minimum level logged: debug, info, warn or error.
+	Level string
+	// Format is json (default) or text.
+	Format string
+	// SampleInitial is how many identical messages per level are logged
+	// each second before sampling starts. Zero disables sampling.
+	SampleInitial int
+	// SampleThereafter logs every Nth identical message once
+	// SampleInitial is exceeded within the same second.
+	SampleThereafter int
+}
+
+// OptionsFromEnv reads LOG_LEVEL, LOG_FORMAT, LOG_SAMPLE_INITIAL and
+// LOG_SAMPLE_THEREAFTER.
+func OptionsFromEnv() Options {
+	opts := Options{
+		Level:            os.Getenv("LOG_LEVEL"),
+		Format:           os.Getenv("LOG_FORMAT"),
+		SampleThereafter: 100,
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_SAMPLE_INITIAL")); err == nil {
+		opts.SampleInitial = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_SAMPLE_THEREAFTER")); err == nil {
+		opts.SampleThereafter = v
+	}
+	return opts
+}
+
+// Init installs a structured logger as the slog and log defaults for the
+// named service.
+func Init(service string, opts Options) {
+	slog.SetDefault(New(os.Stderr, service, opts))
+}
+
+// New builds a structured logger writing to w.
+func New(w io.Writer, service string, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: ParseLevel(opts.Level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(opts.Format, "text") {
+		handler = slog.NewTextHandler(w, handlerOpts)
+	} else {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	}
+
+	handler = &contextHandler{next: handler}
+	if opts.SampleInitial > 0 {
+		handler = newSamplingHandler(handler, opts.SampleInitial, opts.SampleThereafter)
+	}
+
+	return slog.New(handler).With("service", service)
+}
+
+// ParseLevel maps a level name to a slog level, defaulting to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Fatal logs at error level and exits, replacing log.Fatalf.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+type ctxKey struct{}
+
+// With returns a context whose log lines carry the given key/value pairs in
+// addition to any already attached.
+func With(ctx context.Context, args ...any) context.Context {
+	attrs := append([]slog.Attr{}, attrsFrom(ctx)...)
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, ctxKey{}, attrs)
+}
+
+// WithJob tags ctx with a job and, if known, its org.
+func WithJob(ctx context.Context, jobID, orgID string) context.Context {
+	if orgID == "" {
+		return With(ctx, KeyJobID, jobID)
+	}
+	return With(ctx, KeyJobID, jobID, KeyOrgID, orgID)
+}
+
+// WithAgent tags ctx with an agent.
+func WithAgent(ctx context.Context, agentID string) context.Context {
+	return With(ctx, KeyAgentID, agentID)
+}
+
+// WithGroup tags ctx with a job group.
+func WithGroup(ctx context.Context, groupID string) context.Context {
+	return With(ctx, KeyGroupID, groupID)
+}
+
+func attrsFrom(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds correlation attributes and the active trace ID from
+// the record's context.
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := attrsFrom(ctx); len(attrs) > 0 {
+		r.AddAttrs(attrs...)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String(KeyTraceID, sc.TraceID().String()))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
+
+// samplingHandler caps repeated messages: within each one-second window the
+// first `initial` records with the same level and message are kept, then
+// only every `thereafter`th. Warnings and errors are never sampled.
+type samplingHandler struct {
+	next       slog.Handler
+	initial    int
+	thereafter int
+	state      *samplerState
+}
+
+type samplerState struct {
+	mu     sync.Mutex
+	window int64
+	counts map[string]int
+}
+
+func newSamplingHandler(next slog.Handler, initial, thereafter int) *samplingHandler {
+	return &samplingHandler{
+		next:       next,
+		initial:    initial,
+		thereafter: thereafter,
+		state:      &samplerState{counts: make(map[string]int)},
+	}
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn || h.keep(r) {
+		return h.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *samplingHandler) keep(r slog.Record) bool {
+	window := r.Time.Unix()
+	key := r.Level.String() + "|" + r.Message
+
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	if window != h.state.window {
+		h.state.window = window
+		clear(h.state.counts)
+	}
+	h.state.counts[key]++
+	n := h.state.counts[key]
+
+	if n <= h.initial {
+		return true
+	}
+	return h.thereafter > 0 && (n-h.initial)%h.thereafter == 0
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{next: h.next.WithAttrs(attrs), initial: h.initial, thereafter: h.thereafter, state: h.state}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{next: h.next.WithGroup(name), initial: h.initial, thereafter: h.thereafter, state: h.state}
+}
+
+// StdLogger returns a *log.Logger that writes through the default slog
+// logger at the given level, for libraries that want one.
+func StdLogger(level slog.Level) *log.Logger {
+	return slog.NewLogLogger(slog.Default().Handler(), level)
+}
@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"qualgent-test-platform/internal/store"
+)
+
+const namespace = "qualgent"
+
+// Job lifecycle metrics, recorded by job-server.
+var (
+	JobsSubmitted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_submitted_total",
+		Help:      "Number of jobs accepted by SubmitJob.",
+	}, []string{"target", "org_id"})
+
+	JobsCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_completed_total",
+		Help:      "Number of jobs reported COMPLETED by an agent.",
+	}, []string{"target", "org_id"})
+
+	JobsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_failed_total",
+		Help:      "Number of jobs reported FAILED by an agent.",
+	}, []string{"target", "org_id"})
+
+	JobQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_queue_wait_seconds",
+		Help:      "Time from submission until an agent starts running the job.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"target"})
+
+	JobRunDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_run_duration_seconds",
+		Help:      "Time from RUNNING until the job reached a terminal status.",
+		Buckets:   prometheus.ExponentialBuckets(5, 2, 12),
+	}, []string{"target", "status"})
+
+	AgentSlots = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "slots",
+		Help:      "Job group slots this agent offers.",
+	})
+
+	AgentSlotsBusy = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "slots_busy",
+		Help:      "Slots running a job group.",
+	})
+)
+
+// Scheduler metrics.
+var (
+	SchedulerCycleDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "cycle_duration_seconds",
+		Help:      "Duration of scheduler cycles that held the lock.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	SchedulerLockContention = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "lock_contention_total",
+		Help:      "Scheduler cycles skipped because another instance held the lock.",
+	})
+
+	SchedulerLockErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "lock_errors_total",
+		Help:      "Scheduler cycles skipped because the lock could not be acquired.",
+	})
+
+	SchedulerLockLost = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "lock_lost_total",
+		Help:      "Scheduler cycles stopped because the lock could not be renewed.",
+	})
+
+	SchedulerGroupsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "groups_created_total",
+		Help:      "Job groups created by the scheduler.",
+	}, []string{"target"})
+
+	SchedulerGroupsReconciled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "groups_reconciled_total",
+		Help:      "Job groups pushed back onto a dispatch queue by reconciliation.",
+	}, []string{"target"})
+)
+
+// Ingestion metrics.
+var (
+	IngestJobsAccepted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ingest",
+		Name:      "jobs_accepted_total",
+		Help:      "Submitted jobs that passed every pre-flight step.",
+	}, []string{"target"})
+
+	IngestJobsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ingest",
+		Name:      "jobs_rejected_total",
+		Help:      "Submitted jobs failed by a pre-flight step.",
+	}, []string{"target", "step"})
+
+	IngestJobsReconciled = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ingest",
+		Name:      "jobs_reconciled_total",
+		Help:      "Submitted jobs pushed back onto the ingestion queue by reconciliation.",
+	})
+)
+
+// Agent metrics, recorded by appwright-agent.
+var (
+	AgentJobsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "jobs_processed_total",
+		Help:      "Jobs processed by this agent, by final status.",
+	}, []string{"target", "status"})
+
+	AgentTestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "test_duration_seconds",
+		Help:      "Wall-clock time the agent spent executing a test.",
+		Buckets:   prometheus.ExponentialBuckets(5, 2, 12),
+	}, []string{"target", "status"})
+
+	BrowserStackRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "browserstack",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of BrowserStack API requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "code"})
+
+	BrowserStackErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "browserstack",
+		Name:      "errors_total",
+		Help:      "BrowserStack API requests that failed or returned a non-200 status.",
+	}, []string{"operation"})
+
+	BrowserStackUploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "browserstack",
+		Name:      "uploads_total",
+		Help:      "Apps and test suites readied for BrowserStack builds, by whether they were uploaded or already cached.",
+	}, []string{"kind", "result"})
+)
+
+// QueueCollector reports Redis queue lengths at scrape time.
+type QueueCollector struct {
+	queueStore store.QueueStore
+	targets    []string
+
+	ingestion *prometheus.Desc
+	dispatch  *prometheus.Desc
+}
+
+func NewQueueCollector(queueStore store.QueueStore, targets []string) *QueueCollector {
+	return &QueueCollector{
+		queueStore: queueStore,
+		targets:    targets,
+		ingestion: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "queue", "ingestion_length"),
+			"Number of job IDs waiting in the ingestion queue.",
+			nil, nil,
+		),
+		dispatch: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "queue", "dispatch_length"),
+			"Number of job groups waiting in a dispatch queue.",
+			[]string{"target"}, nil,
+		),
+	}
+}
+
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ingestion
+	ch <- c.dispatch
+}
+
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if length, err := c.queueStore.GetIngestionQueueLength(ctx); err != nil {
+		slog.Warn("Failed to read ingestion queue length", "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.ingestion, prometheus.GaugeValue, float64(length))
+	}
+
+	for _, target := range c.targets {
+		length, err := c.queueStore.GetDispatchQueueLength(ctx, target)
+		if err != nil {
+			slog.Warn("Failed to read dispatch queue length", "target", target, "error", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.dispatch, prometheus.GaugeValue, float64(length), target)
+	}
+}
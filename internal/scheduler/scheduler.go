@@ -2,22 +2,47 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/metrics"
 	"qualgent-test-platform/internal/store"
+	"qualgent-test-platform/internal/tracing"
 )
 
 type Scheduler struct {
-	postgresStore *store.PostgresStore
-	redisStore    *store.RedisStore
-	lockKey       string
-	instanceID    string
-	stopChan      chan struct{}
-	wg            sync.WaitGroup
+	jobStore   store.JobStore
+	queueStore store.QueueStore
+	cacheStore store.CacheStore
+	lockKey    string
+	instanceID string
+	config     config.SchedulerConfig
+	stopChan   chan struct{}
+	wg         sync.WaitGroup
+
+	mu    sync.Mutex
+	state State
+
+	// Only touched while holding the scheduler lock
+	lastReconcile time.Time
+}
+
+// State describes the most recent scheduler cycle on this instance.
+type State struct {
+	InstanceID        string
+	Paused            bool
+	LastCycleAt       time.Time
+	LastCycleDuration time.Duration
+	LastCycleJobs     int
+	LastCycleGroups   int
+	LastError         string
 }
 
 type JobGroup struct {
@@ -26,33 +51,72 @@ type JobGroup struct {
 	Jobs         []*store.Job
 	WebAppURL    *string // New field
 	TestType     *string // New field
+	// A group runs on one agent, so its jobs share label constraints
+	RequiredLabels  store.Labels
+	PreferredLabels store.Labels
+	// and one device, since each BrowserStack build targets one
+	Device *string
 }
 
-func NewScheduler(postgresStore *store.PostgresStore, redisStore *store.RedisStore, instanceID string) *Scheduler {
+func NewScheduler(jobStore store.JobStore, queueStore store.QueueStore, cacheStore store.CacheStore, instanceID string, cfg config.SchedulerConfig) *Scheduler {
 	return &Scheduler{
-		postgresStore: postgresStore,
-		redisStore:    redisStore,
-		lockKey:       "scheduler:lock",
-		instanceID:    instanceID,
-		stopChan:      make(chan struct{}),
+		jobStore:   jobStore,
+		queueStore: queueStore,
+		cacheStore: cacheStore,
+		lockKey:    "scheduler:lock",
+		instanceID: instanceID,
+		config:     cfg,
+		stopChan:   make(chan struct{}),
+		state:      State{InstanceID: instanceID},
 	}
 }
 
+// Pause stops every scheduler instance from grouping jobs until Resume.
+func (s *Scheduler) Pause(ctx context.Context) error {
+	if err := s.cacheStore.SetSchedulerPaused(ctx, true); err != nil {
+		return fmt.Errorf("failed to pause scheduler: %w", err)
+	}
+	slog.InfoContext(ctx, "Scheduler paused", "instance_id", s.instanceID)
+	return nil
+}
+
+func (s *Scheduler) Resume(ctx context.Context) error {
+	if err := s.cacheStore.SetSchedulerPaused(ctx, false); err != nil {
+		return fmt.Errorf("failed to resume scheduler: %w", err)
+	}
+	slog.InfoContext(ctx, "Scheduler resumed", "instance_id", s.instanceID)
+	return nil
+}
+
+// State returns a snapshot of this instance's scheduler state.
+func (s *Scheduler) State(ctx context.Context) (State, error) {
+	paused, err := s.cacheStore.IsSchedulerPaused(ctx)
+	if err != nil {
+		return State{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state
+	state.Paused = paused
+	return state, nil
+}
+
 func (s *Scheduler) Start(ctx context.Context) {
 	s.wg.Add(1)
 	go s.run(ctx)
-	log.Printf("Scheduler started with instance ID: %s", s.instanceID)
+	slog.Info("Scheduler started", "instance_id", s.instanceID)
 }
 
 func (s *Scheduler) Stop() {
 	close(s.stopChan)
 	s.wg.Wait()
-	log.Println("Scheduler stopped")
+	slog.Info("Scheduler stopped", "instance_id", s.instanceID)
 }
 
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(s.config.Interval)
 	defer ticker.Stop()
 
 	for {
@@ -68,54 +132,218 @@ func (s *Scheduler) run(ctx context.Context) {
 }
 
 func (s *Scheduler) processBatch(ctx context.Context) {
+	// Skip the cycle while an operator has paused scheduling
+	paused, err := s.cacheStore.IsSchedulerPaused(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to check scheduler pause", "error", err)
+		return
+	}
+	if paused {
+		slog.DebugContext(ctx, "Scheduler is paused, skipping this cycle")
+		return
+	}
+
 	// Try to acquire distributed lock
-	acquired, err := s.redisStore.AcquireLock(ctx, s.lockKey, 60*time.Second)
+	acquired, err := s.cacheStore.AcquireLock(ctx, s.lockKey, s.instanceID, s.config.LockTTL)
 	if err != nil {
-		log.Printf("Failed to acquire lock: %v", err)
+		metrics.SchedulerLockErrors.Inc()
+		slog.ErrorContext(ctx, "Failed to acquire lock", "lock_key", s.lockKey, "error", err)
 		return
 	}
 	if !acquired {
-		log.Println("Another scheduler instance is running, skipping this cycle")
+		metrics.SchedulerLockContention.Inc()
+		slog.DebugContext(ctx, "Another scheduler instance is running, skipping this cycle")
+		s.fillLocalQueues(ctx)
 		return
 	}
 
 	defer func() {
-		if err := s.redisStore.ReleaseLock(ctx, s.lockKey); err != nil {
-			log.Printf("Failed to release lock: %v", err)
+		if err := s.cacheStore.ReleaseLock(ctx, s.lockKey, s.instanceID); err != nil {
+			slog.ErrorContext(ctx, "Failed to release lock", "lock_key", s.lockKey, "error", err)
 		}
 	}()
 
+	// Every write this cycle carries the fence, so it is rejected if the
+	// lock is lost and another instance takes over
+	fence, err := s.jobStore.IssueFence(ctx, s.lockKey)
+	if err != nil {
+		metrics.SchedulerLockErrors.Inc()
+		slog.ErrorContext(ctx, "Failed to issue fencing token", "lock_key", s.lockKey, "error", err)
+		return
+	}
+
+	// Keep the lock alive while the cycle runs, and stop the cycle if it
+	// is lost
+	cycleCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		s.renewLock(cycleCtx, cancel)
+	}()
+	defer func() {
+		cancel()
+		<-renewed
+	}()
+
+	start := time.Now()
+	var jobCount, groupCount int
+	var cycleErr error
+	defer func() {
+		elapsed := time.Since(start)
+		metrics.SchedulerCycleDuration.Observe(elapsed.Seconds())
+
+		s.mu.Lock()
+		s.state.LastCycleAt = start
+		s.state.LastCycleDuration = elapsed
+		s.state.LastCycleJobs = jobCount
+		s.state.LastCycleGroups = groupCount
+		s.state.LastError = ""
+		if cycleErr != nil {
+			s.state.LastError = cycleErr.Error()
+		}
+		s.mu.Unlock()
+	}()
+
 	// Process jobs in batches
-	if err := s.processJobs(ctx); err != nil {
-		log.Printf("Failed to process jobs: %v", err)
+	jobCount, groupCount, cycleErr = s.processJobs(cycleCtx, fence)
+	if cycleErr != nil {
+		slog.ErrorContext(ctx, "Failed to process jobs", "error", cycleErr)
+		if errors.Is(cycleErr, store.ErrFenced) || cycleCtx.Err() != nil {
+			return
+		}
+	}
+
+	// Periodically make sure every group with waiting jobs is queued
+	if time.Since(s.lastReconcile) >= s.config.ReconcileInterval {
+		s.lastReconcile = time.Now()
+		if err := s.reconcile(cycleCtx, false); err != nil {
+			slog.ErrorContext(ctx, "Failed to reconcile dispatch queues", "error", err)
+		}
 	}
 }
 
-func (s *Scheduler) processJobs(ctx context.Context) error {
+// renewLock extends the scheduler lock every third of its TTL until ctx is
+// done, calling lost if the lock can't be renewed.
+func (s *Scheduler) renewLock(ctx context.Context, lost context.CancelFunc) {
+	ticker := time.NewTicker(s.config.LockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewed, err := s.cacheStore.RenewLock(ctx, s.lockKey, s.instanceID, s.config.LockTTL)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil || !renewed {
+			metrics.SchedulerLockLost.Inc()
+			slog.WarnContext(ctx, "Lost scheduler lock, stopping cycle", "lock_key", s.lockKey, "error", err)
+			lost()
+			return
+		}
+	}
+}
+
+// reconcile pushes groups that still have SCHEDULED jobs but are missing
+// from their dispatch queue, e.g. because a push failed or Redis lost data.
+// Entries for groups with nothing left are dropped by FetchJob when popped.
+// A group popped by an in-flight FetchJob may be pushed twice; the claim is
+// atomic, so the extra entry is harmless. local fills this instance's own
+// queues, where missing groups are expected.
+func (s *Scheduler) reconcile(ctx context.Context, local bool) error {
+	groups, err := s.jobStore.ListDispatchableGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	queued := make(map[string]map[uuid.UUID]bool)
+	for _, group := range groups {
+		if _, listed := queued[group.Target]; !listed {
+			ids, err := s.queueStore.ListDispatchQueue(ctx, group.Target)
+			if err != nil {
+				return err
+			}
+			queued[group.Target] = make(map[uuid.UUID]bool, len(ids))
+			for _, id := range ids {
+				queued[group.Target][id] = true
+			}
+		}
+		if queued[group.Target][group.ID] {
+			continue
+		}
+
+		if err := s.queueStore.PushToDispatchQueue(ctx, group.Target, group.ID); err != nil {
+			return fmt.Errorf("failed to push group %s to dispatch queue: %w", group.ID, err)
+		}
+		if local {
+			slog.DebugContext(logging.WithGroup(ctx, group.ID.String()), "Queued job group on this instance", "target", group.Target)
+			continue
+		}
+		metrics.SchedulerGroupsReconciled.WithLabelValues(group.Target).Inc()
+		slog.WarnContext(logging.WithGroup(ctx, group.ID.String()), "Requeued job group missing from dispatch queue", "target", group.Target)
+	}
+	return nil
+}
+
+// degradable is a queue store that may fall back to queues of its own, such
+// as store.FailoverCache while Redis is down.
+type degradable interface {
+	Degraded() bool
+}
+
+// fillLocalQueues queues the groups waiting in the database on this
+// instance while its dispatch queues are its own. Only the lock holder
+// pushes the groups it creates, so without this, agents connected to any
+// other instance would get no work until Redis is back.
+func (s *Scheduler) fillLocalQueues(ctx context.Context) {
+	if queues, ok := s.queueStore.(degradable); !ok || !queues.Degraded() {
+		return
+	}
+	if err := s.reconcile(ctx, true); err != nil {
+		slog.WarnContext(ctx, "Failed to fill local dispatch queues", "error", err)
+	}
+}
+
+func (s *Scheduler) processJobs(ctx context.Context, fence store.Fence) (int, int, error) {
 	// Get pending jobs from database
-	jobs, err := s.postgresStore.GetPendingJobs(ctx, 10)
+	jobs, err := s.jobStore.GetPendingJobs(ctx, s.config.BatchSize)
 	if err != nil {
-		return fmt.Errorf("failed to get pending jobs: %w", err)
+		return 0, 0, fmt.Errorf("failed to get pending jobs: %w", err)
 	}
 
 	if len(jobs) == 0 {
-		return nil
+		return 0, 0, nil
 	}
 
-	log.Printf("Processing %d pending jobs", len(jobs))
+	slog.InfoContext(ctx, "Processing pending jobs", "count", len(jobs))
 
 	// Group jobs by app_version_id and target
 	jobGroups := s.groupJobs(jobs)
 
 	// Create job groups and dispatch them
+	created := 0
 	for _, group := range jobGroups {
-		if err := s.createAndDispatchGroup(ctx, group); err != nil {
-			log.Printf("Failed to create and dispatch group: %v", err)
+		if err := s.createAndDispatchGroup(ctx, group, fence); err != nil {
+			// Another instance holds the lock now; leave the rest to it
+			if errors.Is(err, store.ErrFenced) || ctx.Err() != nil {
+				return len(jobs), created, err
+			}
+			// Its jobs were requeued or failed since they were read
+			if errors.Is(err, store.ErrEmptyGroup) {
+				slog.InfoContext(ctx, "Skipped job group with no pending jobs left", "target", group.Target, "jobs", len(group.Jobs))
+				continue
+			}
+			slog.ErrorContext(ctx, "Failed to create and dispatch group", "target", group.Target, "jobs", len(group.Jobs), "error", err)
 			continue
 		}
+		created++
 	}
 
-	return nil
+	return len(jobs), created, nil
 }
 
 func (s *Scheduler) groupJobs(jobs []*store.Job) []*JobGroup {
@@ -128,13 +356,20 @@ func (s *Scheduler) groupJobs(jobs []*store.Job) []*JobGroup {
 		} else {
 			key = fmt.Sprintf("%s:%s", job.AppVersionID, job.Target)
 		}
+		key += fmt.Sprintf(":%q:%q", job.RequiredLabels.String(), job.PreferredLabels.String())
+		if job.Device != nil {
+			key += fmt.Sprintf(":%q", *job.Device)
+		}
 		
 		if group, exists := groupMap[key]; exists {
 			group.Jobs = append(group.Jobs, job)
 		} else {
 			newGroup := &JobGroup{
-				Target:       job.Target,
-				Jobs:       []*store.Job{job},
+				Target:          job.Target,
+				Jobs:            []*store.Job{job},
+				RequiredLabels:  job.RequiredLabels,
+				PreferredLabels: job.PreferredLabels,
+				Device:          job.Device,
 			}
 			if job.Target == "web" {
 				newGroup.WebAppURL = job.WebAppURL
@@ -155,36 +390,60 @@ func (s *Scheduler) groupJobs(jobs []*store.Job) []*JobGroup {
 	return groups
 }
 
-func (s *Scheduler) createAndDispatchGroup(ctx context.Context, group *JobGroup) error {
-	// Create job group in database
+func (s *Scheduler) createAndDispatchGroup(ctx context.Context, group *JobGroup, fence store.Fence) error {
+	var jobIDs []uuid.UUID
+	for _, job := range group.Jobs {
+		jobIDs = append(jobIDs, job.ID)
+	}
+
+	// Create job group in database, moving its jobs into it under the
+	// scheduler's fence
 	jobGroup := &store.JobGroup{
-		AppVersionID: group.AppVersionID,
-		Target:       group.Target,
-		Status:      "SCHEDULED",
+		AppVersionID:    group.AppVersionID,
+		Target:          group.Target,
+		Status:          "SCHEDULED",
+		WebAppURL:       group.WebAppURL,
+		TestType:        group.TestType,
+		RequiredLabels:  group.RequiredLabels,
+		PreferredLabels: group.PreferredLabels,
+		Device:          group.Device,
 	}
 
-	if err := s.postgresStore.CreateJobGroup(ctx, jobGroup); err != nil {
+	if err := s.jobStore.CreateJobGroup(ctx, jobGroup, jobIDs, fence); err != nil {
 		return fmt.Errorf("failed to create job group: %w", err)
 	}
+	metrics.SchedulerGroupsCreated.WithLabelValues(group.Target).Inc()
 
-	// Update jobs to point to the group
-	var jobIDs []uuid.UUID
-	for _, job := range group.Jobs {
-		jobIDs = append(jobIDs, job.ID)
+	// Hand the group to agents; reconciliation retries a failed push
+	if err := s.queueStore.PushToDispatchQueue(ctx, group.Target, jobGroup.ID); err != nil {
+		slog.WarnContext(logging.WithGroup(ctx, jobGroup.ID.String()), "Failed to push job group to dispatch queue", "target", group.Target, "error", err)
 	}
 
-	if err := s.postgresStore.UpdateJobsToGroup(ctx, jobIDs, jobGroup.ID); err != nil {
-		return fmt.Errorf("failed to update jobs to group: %w", err)
+	// Mark the grouping step in each job's trace
+	for _, job := range group.Jobs {
+		_, span := tracing.Tracer("scheduler").Start(tracing.JobContext(ctx, job.TraceID, job.SpanID), "Scheduler.group")
+		span.SetAttributes(
+			attribute.String("job.id", job.ID.String()),
+			attribute.String("job_group.id", jobGroup.ID.String()),
+			attribute.Int("job_group.size", len(group.Jobs)),
+		)
+		span.End()
 	}
 
-	log.Printf("Created job group %s with %d jobs for target %s",		jobGroup.ID, len(group.Jobs), group.Target)
+	slog.InfoContext(logging.WithGroup(ctx, jobGroup.ID.String()), "Created job group", "jobs", len(group.Jobs), "target", group.Target)
 
 	return nil
 }
 
 // GetJobGroup retrieves a job group with all its jobs
 func (s *Scheduler) GetJobGroup(ctx context.Context, groupID uuid.UUID) (*store.JobGroup, []*store.Job, error) {
-	// This would need to be implemented in the store layer
-	// For now, we'll return a placeholder
-	return nil, nil, fmt.Errorf("not implemented")
+	group, err := s.jobStore.GetJobGroup(ctx, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	jobs, err := s.jobStore.ListGroupJobs(ctx, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return group, jobs, nil
 }
\ No newline at end of file
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/scheduler"
+	"qualgent-test-platform/internal/store"
+)
+
+// AdminService implements operator actions that would otherwise need SQL
+// against the jobs, job_groups and agents tables.
+type AdminService struct {
+	pb.UnimplementedAdminServiceServer
+	jobStore   store.JobStore
+	agentStore store.AgentStore
+	queueStore store.QueueStore
+	cacheStore store.CacheStore
+	scheduler  *scheduler.Scheduler
+	sessions   *Sessions
+	cache      config.Cache
+}
+
+func NewAdminService(jobStore store.JobStore, agentStore store.AgentStore, queueStore store.QueueStore, cacheStore store.CacheStore, sched *scheduler.Scheduler, sessions *Sessions, cache config.Cache) *AdminService {
+	return &AdminService{
+		jobStore:   jobStore,
+		agentStore: agentStore,
+		queueStore: queueStore,
+		cacheStore: cacheStore,
+		scheduler:  sched,
+		sessions:   sessions,
+		cache:      cache,
+	}
+}
+
+func (s *AdminService) RequeueJob(ctx context.Context, req *pb.RequeueJobRequest) (*pb.AdminJobResponse, error) {
+	jobID, err := uuid.Parse(req.JobId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid job_id format")
+	}
+	ctx = logging.WithJob(ctx, req.JobId, "")
+
+	if err := s.jobStore.RequeueJob(ctx, jobID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "job not found")
+		}
+		slog.ErrorContext(ctx, "Failed to requeue job", "error", err)
+		return nil, status.Error(codes.Internal, "failed to requeue job")
+	}
+
+	if err := s.cacheStore.SetJobStatus(ctx, jobID, "PENDING", s.cache.JobStatusTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to update job status cache", "error", err)
+	}
+	s.sessions.CancelJob(ctx, jobID, "requeued by operator")
+
+	slog.InfoContext(ctx, "Admin requeued job")
+	return &pb.AdminJobResponse{JobId: req.JobId, Status: "PENDING"}, nil
+}
+
+func (s *AdminService) FailJob(ctx context.Context, req *pb.FailJobRequest) (*pb.AdminJobResponse, error) {
+	jobID, err := uuid.Parse(req.JobId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid job_id format")
+	}
+	ctx = logging.WithJob(ctx, req.JobId, "")
+
+	if _, err := s.jobStore.GetJob(ctx, jobID); err != nil {
+		return nil, status.Error(codes.NotFound, "job not found")
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "failed by operator"
+	}
+	result := &store.JobResult{
+		Status:       "FAILED",
+		ErrorMessage: &reason,
+	}
+	if err := s.jobStore.UpdateJobResult(ctx, jobID, result); err != nil {
+		slog.ErrorContext(ctx, "Failed to fail job", "error", err)
+		return nil, status.Error(codes.Internal, "failed to fail job")
+	}
+
+	if err := s.cacheStore.SetJobStatus(ctx, jobID, "FAILED", s.cache.JobStatusTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to update job status cache", "error", err)
+	}
+	s.sessions.CancelJob(ctx, jobID, reason)
+
+	slog.InfoContext(ctx, "Admin failed job", "reason", reason)
+	return &pb.AdminJobResponse{JobId: req.JobId, Status: "FAILED"}, nil
+}
+
+func (s *AdminService) DrainAgent(ctx context.Context, req *pb.AdminAgentRequest) (*pb.AdminAgentResponse, error) {
+	agentID, err := uuid.Parse(req.AgentId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid agent_id format")
+	}
+	ctx = logging.WithAgent(ctx, req.AgentId)
+
+	if err := s.agentStore.UpdateAgentStatus(ctx, agentID, "DRAINING"); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "agent not found")
+		}
+		slog.ErrorContext(ctx, "Failed to drain agent", "error", err)
+		return nil, status.Error(codes.Internal, "failed to drain agent")
+	}
+	s.sessions.Drain(agentID)
+
+	slog.InfoContext(ctx, "Admin drained agent")
+	return &pb.AdminAgentResponse{AgentId: req.AgentId, Status: "DRAINING"}, nil
+}
+
+func (s *AdminService) EvictAgent(ctx context.Context, req *pb.AdminAgentRequest) (*pb.AdminAgentResponse, error) {
+	agentID, err := uuid.Parse(req.AgentId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid agent_id format")
+	}
+	ctx = logging.WithAgent(ctx, req.AgentId)
+
+	if err := s.agentStore.UpdateAgentStatus(ctx, agentID, "OFFLINE"); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "agent not found")
+		}
+		slog.ErrorContext(ctx, "Failed to evict agent", "error", err)
+		return nil, status.Error(codes.Internal, "failed to evict agent")
+	}
+
+	if err := s.cacheStore.RemoveAgentHeartbeat(ctx, agentID); err != nil {
+		slog.WarnContext(ctx, "Failed to remove agent heartbeat", "error", err)
+	}
+
+	requeued, err := handBackJobs(ctx, s.jobStore, s.cacheStore, s.cache.JobStatusTTL, s.sessions, agentID, "agent evicted")
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to requeue evicted agent's jobs", "error", err)
+		return nil, status.Error(codes.Internal, "agent evicted but its jobs could not be requeued")
+	}
+
+	slog.InfoContext(ctx, "Admin evicted agent", "requeued_jobs", requeued)
+	return &pb.AdminAgentResponse{AgentId: req.AgentId, Status: "OFFLINE", RequeuedJobs: int32(requeued)}, nil
+}
+
+func (s *AdminService) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
+	agents, err := s.agentStore.ListAgents(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list agents", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list agents")
+	}
+
+	response := &pb.ListAgentsResponse{Agents: make([]*pb.AgentInfo, 0, len(agents))}
+	for _, agent := range agents {
+		if agent.Status == "OFFLINE" && !req.IncludeOffline {
+			continue
+		}
+		alive, err := s.cacheStore.IsAgentAlive(ctx, agent.ID)
+		if err != nil {
+			slog.WarnContext(logging.WithAgent(ctx, agent.ID.String()), "Failed to check agent heartbeat", "error", err)
+		}
+		response.Agents = append(response.Agents, &pb.AgentInfo{
+			AgentId:          agent.ID.String(),
+			Hostname:         agent.Hostname,
+			TargetCapability: agent.TargetCapability,
+			Status:           agent.Status,
+			Labels:           agent.Labels,
+			Alive:            alive,
+			RegisteredAt:     timestamppb.New(agent.CreatedAt),
+			UpdatedAt:        timestamppb.New(agent.UpdatedAt),
+		})
+	}
+	return response, nil
+}
+
+func (s *AdminService) PauseScheduler(ctx context.Context, req *pb.PauseSchedulerRequest) (*pb.SchedulerStateResponse, error) {
+	if err := s.scheduler.Pause(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to pause scheduler", "error", err)
+		return nil, status.Error(codes.Internal, "failed to pause scheduler")
+	}
+	return s.GetSchedulerState(ctx, &pb.GetSchedulerStateRequest{})
+}
+
+func (s *AdminService) ResumeScheduler(ctx context.Context, req *pb.ResumeSchedulerRequest) (*pb.SchedulerStateResponse, error) {
+	if err := s.scheduler.Resume(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to resume scheduler", "error", err)
+		return nil, status.Error(codes.Internal, "failed to resume scheduler")
+	}
+	return s.GetSchedulerState(ctx, &pb.GetSchedulerStateRequest{})
+}
+
+func (s *AdminService) GetSchedulerState(ctx context.Context, req *pb.GetSchedulerStateRequest) (*pb.SchedulerStateResponse, error) {
+	state, err := s.scheduler.State(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to read scheduler state", "error", err)
+		return nil, status.Error(codes.Internal, "failed to read scheduler state")
+	}
+
+	response := &pb.SchedulerStateResponse{
+		InstanceId:          state.InstanceID,
+		Paused:              state.Paused,
+		LastCycleDurationMs: state.LastCycleDuration.Milliseconds(),
+		LastCycleJobs:       int32(state.LastCycleJobs),
+		LastCycleGroups:     int32(state.LastCycleGroups),
+		LastError:           state.LastError,
+	}
+	if !state.LastCycleAt.IsZero() {
+		response.LastCycleAt = timestamppb.New(state.LastCycleAt)
+	}
+
+	if counts, err := s.jobStore.CountJobsByStatus(ctx); err != nil {
+		slog.WarnContext(ctx, "Failed to count jobs", "error", err)
+	} else {
+		response.JobCounts = counts
+	}
+
+	if length, err := s.queueStore.GetIngestionQueueLength(ctx); err != nil {
+		slog.WarnContext(ctx, "Failed to read ingestion queue length", "error", err)
+	} else {
+		response.IngestionQueueLength = length
+	}
+
+	return response, nil
+}
@@ -0,0 +1,584 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/store"
+)
+
+// Sessions tracks the agents connected over AgentSession so that operator
+// actions reach the agent running a job straight away.
+type Sessions struct {
+	mu      sync.Mutex
+	byAgent map[uuid.UUID]*agentSession
+}
+
+func NewSessions() *Sessions {
+	return &Sessions{byAgent: make(map[uuid.UUID]*agentSession)}
+}
+
+// agentSession is one connected agent. jobs maps the jobs assigned over the
+// session that haven't finished to their group, and groups counts each
+// group's unfinished jobs. A group holds its slot until all its jobs are
+// done, and reserved counts slots taken by groups being claimed, so a slot
+// is free while len(groups)+reserved < slots. The agent's slots are shared
+// by all its targets.
+type agentSession struct {
+	agentID uuid.UUID
+	targets []string
+	labels  store.Labels
+	stream  pb.JobService_AgentSessionServer
+	sendMu  sync.Mutex
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	slots    int
+	maxSlots int // From the max_parallelism label; 0 if unset
+	draining bool
+	jobs     map[uuid.UUID]uuid.UUID
+	groups   map[uuid.UUID]int
+	reserved int
+	// Closed and replaced when a slot frees up or the session's state
+	// changes, waking every dispatch loop
+	wake chan struct{}
+}
+
+func (a *agentSession) send(msg *pb.ServerMessage) error {
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+	return a.stream.Send(msg)
+}
+
+// notifyLocked wakes the session's dispatch loops. a.mu must be held.
+func (a *agentSession) notifyLocked() {
+	close(a.wake)
+	a.wake = make(chan struct{})
+}
+
+// serves reports whether the agent runs jobs for target.
+func (a *agentSession) serves(target string) bool {
+	return slices.Contains(a.targets, target)
+}
+
+// hasFreeSlot reports whether the session may be assigned another job.
+func (a *agentSession) hasFreeSlot() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.freeLocked()
+}
+
+func (a *agentSession) freeLocked() bool {
+	return !a.draining && len(a.groups)+a.reserved < a.slots
+}
+
+// waitForSlot blocks until the session has a free slot, reporting false if
+// ctx is done first.
+func (a *agentSession) waitForSlot(ctx context.Context) bool {
+	for {
+		a.mu.Lock()
+		free, wake := a.freeLocked(), a.wake
+		a.mu.Unlock()
+		if free {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-wake:
+		}
+	}
+}
+
+// reserve takes a free slot for a group about to be claimed, reporting
+// whether there was one. The reservation ends with assign or unreserve.
+func (a *agentSession) reserve() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.freeLocked() {
+		return false
+	}
+	a.reserved++
+	return true
+}
+
+func (a *agentSession) unreserve() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reserved--
+	a.notifyLocked()
+}
+
+// setSlots changes the session's capacity, capped by its max_parallelism.
+func (a *agentSession) setSlots(slots int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.slots = max(slots, 0)
+	if a.maxSlots > 0 {
+		a.slots = min(a.slots, a.maxSlots)
+	}
+	a.notifyLocked()
+}
+
+// assign records a group's jobs as running on the session, in the slot
+// reserved for it.
+func (a *agentSession) assign(groupID uuid.UUID, jobs []*store.Job) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reserved--
+	for _, job := range jobs {
+		a.jobs[job.ID] = groupID
+	}
+	a.groups[groupID] += len(jobs)
+}
+
+// holds reports whether a job is still running on the session.
+func (a *agentSession) holds(jobID uuid.UUID) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.jobs[jobID]
+	return ok
+}
+
+// finish removes a job from the session, reporting whether it was there.
+// The job's group frees its slot once its last job is finished.
+func (a *agentSession) finish(jobID uuid.UUID) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	groupID, ok := a.jobs[jobID]
+	if !ok {
+		return false
+	}
+	delete(a.jobs, jobID)
+	a.groups[groupID]--
+	if a.groups[groupID] == 0 {
+		delete(a.groups, groupID)
+		a.notifyLocked()
+	}
+	return true
+}
+
+// takeJobs empties the session's in-flight jobs and returns them.
+func (a *agentSession) takeJobs() []uuid.UUID {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	jobs := make([]uuid.UUID, 0, len(a.jobs))
+	for jobID := range a.jobs {
+		jobs = append(jobs, jobID)
+	}
+	a.jobs = make(map[uuid.UUID]uuid.UUID)
+	a.groups = make(map[uuid.UUID]int)
+	a.notifyLocked()
+	return jobs
+}
+
+// add registers a session, ending any earlier session of the same agent.
+func (s *Sessions) add(session *agentSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if previous, ok := s.byAgent[session.agentID]; ok {
+		previous.cancel()
+	}
+	s.byAgent[session.agentID] = session
+}
+
+func (s *Sessions) remove(session *agentSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byAgent[session.agentID] == session {
+		delete(s.byAgent, session.agentID)
+	}
+}
+
+func (s *Sessions) get(agentID uuid.UUID) *agentSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.byAgent[agentID]
+}
+
+// preferredElsewhere reports whether a connected agent other than agentID,
+// serving the group's target with a free slot, has every label the group
+// requires and prefers.
+func (s *Sessions) preferredElsewhere(agentID uuid.UUID, group *store.JobGroup) bool {
+	if len(group.PreferredLabels) == 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	sessions := make([]*agentSession, 0, len(s.byAgent))
+	for _, session := range s.byAgent {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		if session.agentID == agentID || !session.serves(group.Target) || !session.hasFreeSlot() {
+			continue
+		}
+		if session.labels.Matches(group.RequiredLabels) && session.labels.Matches(group.PreferredLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelJob tells the agent running a job to stop it. The caller has
+// already moved the job on, so the agent's slot is freed without waiting
+// for a result. It reports whether a connected agent had the job.
+func (s *Sessions) CancelJob(ctx context.Context, jobID uuid.UUID, reason string) bool {
+	s.mu.Lock()
+	sessions := make([]*agentSession, 0, len(s.byAgent))
+	for _, session := range s.byAgent {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		if !session.finish(jobID) {
+			continue
+		}
+		s.sendCancellation(ctx, session, jobID, reason)
+		return true
+	}
+	return false
+}
+
+// Drain stops new assignments to a connected agent; its running jobs
+// carry on.
+func (s *Sessions) Drain(agentID uuid.UUID) {
+	if session := s.get(agentID); session != nil {
+		session.mu.Lock()
+		session.draining = true
+		session.notifyLocked()
+		session.mu.Unlock()
+	}
+}
+
+// Close cancels every job the agent is running and ends its session. It
+// returns the cancelled jobs for the caller to requeue.
+func (s *Sessions) Close(ctx context.Context, agentID uuid.UUID, reason string) []uuid.UUID {
+	session := s.get(agentID)
+	if session == nil {
+		return nil
+	}
+	jobs := session.takeJobs()
+	for _, jobID := range jobs {
+		s.sendCancellation(ctx, session, jobID, reason)
+	}
+	session.cancel()
+	return jobs
+}
+
+// CloseAll ends every session, for server shutdown. Jobs in flight are
+// requeued as each session ends.
+func (s *Sessions) CloseAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.byAgent {
+		session.cancel()
+	}
+}
+
+func (s *Sessions) sendCancellation(ctx context.Context, session *agentSession, jobID uuid.UUID, reason string) {
+	err := session.send(&pb.ServerMessage{Message: &pb.ServerMessage_Cancellation{
+		Cancellation: &pb.JobCancellation{JobId: jobID.String(), Reason: reason},
+	}})
+	if err != nil {
+		slog.WarnContext(logging.WithAgent(ctx, session.agentID.String()), "Failed to send job cancellation", logging.KeyJobID, jobID.String(), "error", err)
+	}
+}
+
+// AgentSession serves an agent's session stream: it hands the agent whole
+// job groups from its target's dispatch queue while it has free slots, and
+// applies the heartbeats and progress the agent sends. When the stream
+// ends, jobs the agent hadn't finished are requeued.
+func (s *JobService) AgentSession(stream pb.JobService_AgentSessionServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	hello := first.GetHello()
+	if hello == nil {
+		return status.Error(codes.InvalidArgument, "first session message must be a hello")
+	}
+	agentID, err := uuid.Parse(hello.AgentId)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid agent_id format")
+	}
+	if hello.Slots < 0 {
+		return status.Error(codes.InvalidArgument, "slots must not be negative")
+	}
+
+	ctx, cancel := context.WithCancel(logging.WithAgent(stream.Context(), hello.AgentId))
+	defer cancel()
+
+	agent, err := s.agentStore.GetAgent(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return status.Error(codes.NotFound, "agent not registered")
+		}
+		slog.ErrorContext(ctx, "Failed to load agent", "error", err)
+		return status.Error(codes.Internal, "failed to load agent")
+	}
+	if agent.Status == "OFFLINE" {
+		return status.Error(codes.FailedPrecondition, "agent is OFFLINE")
+	}
+	// Validated at registration
+	maxSlots, _ := maxParallelism(agent.Labels)
+
+	session := &agentSession{
+		agentID:  agentID,
+		targets:  agent.Targets(),
+		labels:   agent.Labels,
+		stream:   stream,
+		cancel:   cancel,
+		maxSlots: maxSlots,
+		draining: agent.Status == "DRAINING",
+		jobs:     make(map[uuid.UUID]uuid.UUID),
+		groups:   make(map[uuid.UUID]int),
+		wake:     make(chan struct{}),
+	}
+	session.setSlots(int(hello.Slots))
+	s.sessions.add(session)
+	defer s.endSession(ctx, session)
+
+	s.heartbeat(ctx, agentID)
+	slog.InfoContext(ctx, "Agent session started", "targets", session.targets, "slots", session.slots, "labels", session.labels.String(), "draining", session.draining)
+
+	// Receive on a separate goroutine so that cancelling the session ends
+	// the stream even while Recv is blocked
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			s.handleAgentMessage(ctx, session, msg)
+		}
+	}()
+
+	// One dispatch loop per target, sharing the session's slots
+	var dispatching sync.WaitGroup
+	for _, target := range session.targets {
+		dispatching.Add(1)
+		go func() {
+			defer dispatching.Done()
+			s.dispatchLoop(ctx, session, target)
+		}()
+	}
+
+	select {
+	case err = <-recvErr:
+		if err == io.EOF {
+			err = nil
+		}
+	case <-ctx.Done():
+		err = nil
+	}
+	cancel()
+	dispatching.Wait()
+	return err
+}
+
+func (s *JobService) handleAgentMessage(ctx context.Context, session *agentSession, msg *pb.AgentMessage) {
+	switch m := msg.Message.(type) {
+	case *pb.AgentMessage_Heartbeat:
+		s.heartbeat(ctx, session.agentID)
+
+	case *pb.AgentMessage_Capacity:
+		session.setSlots(int(m.Capacity.Slots))
+		slog.DebugContext(ctx, "Agent capacity changed", "slots", m.Capacity.Slots)
+
+	case *pb.AgentMessage_Progress:
+		jobID, err := uuid.Parse(m.Progress.JobId)
+		if err != nil {
+			slog.WarnContext(ctx, "Ignoring progress with invalid job_id", logging.KeyJobID, m.Progress.JobId)
+			return
+		}
+		statusStr := statusToString(m.Progress.Status)
+		jobCtx := logging.WithJob(ctx, m.Progress.JobId, "")
+
+		// Only jobs this session still holds; a cancelled job's late
+		// result must not overwrite what the operator did with it
+		if !session.holds(jobID) {
+			slog.WarnContext(jobCtx, "Ignoring progress for a job not assigned to this session", "status", statusStr)
+			return
+		}
+
+		finished := statusStr == "COMPLETED" || statusStr == "FAILED"
+		var result *store.JobResult
+		if finished {
+			result = progressResult(statusStr, m.Progress)
+		}
+		if err := s.applyJobStatus(jobCtx, jobID, statusStr, session.agentID.String(), result); err != nil {
+			return
+		}
+		if finished {
+			session.finish(jobID)
+		}
+
+	default:
+		slog.WarnContext(ctx, "Ignoring unexpected session message", "type", fmt.Sprintf("%T", m))
+	}
+}
+
+func (s *JobService) heartbeat(ctx context.Context, agentID uuid.UUID) {
+	if err := s.cacheStore.UpdateAgentHeartbeat(ctx, agentID, s.cache.HeartbeatTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to update agent heartbeat", "error", err)
+	}
+}
+
+// maxParallelism reads an agent's max_parallelism label, which caps the
+// slots it may use. It returns 0 when the label is unset.
+func maxParallelism(labels store.Labels) (int, error) {
+	value, ok := labels["max_parallelism"]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("max_parallelism must be a positive integer, got %q", value)
+	}
+	return n, nil
+}
+
+// progressResult turns a final JobProgress into the job's stored result.
+func progressResult(statusStr string, progress *pb.JobProgress) *store.JobResult {
+	result := &store.JobResult{Status: statusStr}
+	if progress.SessionId != "" {
+		result.SessionID = &progress.SessionId
+	}
+	if progress.LogsUrl != "" {
+		result.LogsURL = &progress.LogsUrl
+	}
+	if progress.VideoUrl != "" {
+		result.VideoURL = &progress.VideoUrl
+	}
+	if progress.ErrorMessage != "" {
+		result.ErrorMessage = &progress.ErrorMessage
+	}
+	if progress.TestDuration > 0 {
+		result.TestDuration = &progress.TestDuration
+	}
+	return result
+}
+
+// dispatchLoop assigns job groups for one target to the session whenever
+// it has a free slot. Each group goes to this agent alone.
+func (s *JobService) dispatchLoop(ctx context.Context, session *agentSession, target string) {
+	for {
+		if !session.waitForSlot(ctx) {
+			return
+		}
+
+		group, jobs, err := s.nextGroup(ctx, session, target, sessionPopWait)
+		if ctx.Err() != nil {
+			if group != nil {
+				session.unreserve()
+			}
+			for _, job := range jobs {
+				s.requeueJob(ctx, job.ID)
+			}
+			return
+		}
+		if code := status.Code(err); code == codes.NotFound || code == codes.ResourceExhausted {
+			continue
+		}
+		if err != nil {
+			// nextJob has logged it; don't spin on a failing store
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		session.assign(group.ID, jobs)
+
+		assignment := dispatchGroup(ctx, group, jobs)
+		err = session.send(&pb.ServerMessage{Message: &pb.ServerMessage_GroupAssignment{GroupAssignment: assignment}})
+		if err != nil {
+			slog.WarnContext(logging.WithGroup(ctx, group.ID.String()), "Failed to send group assignment", "error", err)
+			// endSession requeues its jobs with the session's others
+			return
+		}
+	}
+}
+
+// endSession unregisters the session and requeues the jobs it hadn't
+// finished, since the agent stops them when it loses the session.
+func (s *JobService) endSession(ctx context.Context, session *agentSession) {
+	s.sessions.remove(session)
+
+	ctx = context.WithoutCancel(ctx)
+	if err := s.cacheStore.RemoveAgentHeartbeat(ctx, session.agentID); err != nil {
+		slog.WarnContext(ctx, "Failed to remove agent heartbeat", "error", err)
+	}
+
+	jobs := session.takeJobs()
+	for _, jobID := range jobs {
+		s.requeueJob(ctx, jobID)
+	}
+	slog.InfoContext(ctx, "Agent session ended", "requeued_jobs", len(jobs))
+}
+
+// requeueJob sends an unfinished job back through scheduling.
+func (s *JobService) requeueJob(ctx context.Context, jobID uuid.UUID) {
+	ctx = logging.WithJob(context.WithoutCancel(ctx), jobID.String(), "")
+	if _, err := requeueUnfinished(ctx, s.jobStore, s.cacheStore, s.cache.JobStatusTTL, jobID); err != nil {
+		slog.ErrorContext(ctx, "Failed to requeue job", "error", err)
+	}
+}
+
+// requeueUnfinished returns a job to the queue and reports whether it did,
+// leaving it alone if it has already finished.
+func requeueUnfinished(ctx context.Context, jobStore store.JobStore, cacheStore store.CacheStore, statusTTL time.Duration, jobID uuid.UUID) (bool, error) {
+	// The agent's final status may land as the session ends; the store
+	// checks for it in the same update
+	requeued, err := jobStore.RequeueUnfinishedJob(ctx, jobID)
+	if err != nil || !requeued {
+		return false, err
+	}
+	if err := cacheStore.SetJobStatus(ctx, jobID, "PENDING", statusTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to update job status cache", "error", err)
+	}
+	return true, nil
+}
+
+// handBackJobs stops whatever an agent is running over its session, then
+// requeues that along with any jobs in groups assigned to it. It returns
+// how many jobs were requeued.
+func handBackJobs(ctx context.Context, jobStore store.JobStore, cacheStore store.CacheStore, statusTTL time.Duration, sessions *Sessions, agentID uuid.UUID, reason string) (int64, error) {
+	var requeued int64
+	for _, jobID := range sessions.Close(ctx, agentID, reason) {
+		ok, err := requeueUnfinished(ctx, jobStore, cacheStore, statusTTL, jobID)
+		if err != nil {
+			return requeued, fmt.Errorf("failed to requeue job %s: %w", jobID, err)
+		}
+		if ok {
+			requeued++
+		}
+	}
+
+	grouped, err := jobStore.RequeueAgentJobs(ctx, agentID)
+	if err != nil {
+		return requeued, err
+	}
+	return requeued + grouped, nil
+}
@@ -2,28 +2,59 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/logging"
+	"qualgent-test-platform/internal/metrics"
 	"qualgent-test-platform/internal/store"
+	"qualgent-test-platform/internal/tracing"
 	pb "qualgent-test-platform/api/proto"
 )
 
+const (
+	minFetchWait = time.Second
+	maxFetchWait = 30 * time.Second
+	// sessionPopWait bounds each blocking pop of a session's dispatch loop.
+	// A Redis BRPOP doesn't return when its context is cancelled, so this is
+	// also how long a closed session may wait on one.
+	sessionPopWait = 2 * time.Second
+	// rescanDelay spaces out passes over a dispatch queue whose groups
+	// all need labels the fetching agent lacks
+	rescanDelay = time.Second
+	// maxLabelLength bounds label keys and values
+	maxLabelLength = 128
+	// maxDevices bounds the device matrix of one submission
+	maxDevices = 20
+)
+
 type JobService struct {
 	pb.UnimplementedJobServiceServer
-	postgresStore *store.PostgresStore
-	redisStore    *store.RedisStore
+	jobStore   store.JobStore
+	agentStore store.AgentStore
+	queueStore store.QueueStore
+	cacheStore store.CacheStore
+	sessions   *Sessions
+	cache      config.Cache
 }
 
-func NewJobService(postgresStore *store.PostgresStore, redisStore *store.RedisStore) *JobService {
+func NewJobService(jobStore store.JobStore, agentStore store.AgentStore, queueStore store.QueueStore, cacheStore store.CacheStore, sessions *Sessions, cache config.Cache) *JobService {
 	return &JobService{
-		postgresStore: postgresStore,
-		redisStore:    redisStore,
+		jobStore:   jobStore,
+		agentStore: agentStore,
+		queueStore: queueStore,
+		cacheStore: cacheStore,
+		sessions:   sessions,
+		cache:      cache,
 	}
 }
 
@@ -41,48 +72,83 @@ func (s *JobService) SubmitJob(ctx context.Context, req *pb.SubmitJobRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "app_version_id is required for non-web targets")
 	}
 
+	if err := validateLabels("required_labels", req.RequiredLabels); err != nil {
+		return nil, err
+	}
+	if err := validateLabels("preferred_labels", req.PreferredLabels); err != nil {
+		return nil, err
+	}
+	devices, err := parseDevices(req.Devices)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) > 0 && req.Target == pb.Target_WEB {
+		return nil, status.Error(codes.InvalidArgument, "devices are not supported for web target")
+	}
+
 	// Check idempotency if provided
 	if req.IdempotencyKey != "" {
-		processed, err := s.redisStore.CheckIdempotency(ctx, req.IdempotencyKey)
+		processed, err := s.cacheStore.CheckIdempotency(ctx, req.IdempotencyKey)
 		if err != nil {
-			log.Printf("Failed to check idempotency: %v", err)
+			slog.WarnContext(ctx, "Failed to check idempotency", "idempotency_key", req.IdempotencyKey, "error", err)
 		} else if processed {
 			return nil, status.Error(codes.AlreadyExists, "job with this idempotency key already exists")
 		}
 	}
 
-	// Create job
+	// Create job, remembering the span it was submitted under
+	testType := testTypeToString(req.TestType)
+	traceID, spanID := tracing.IDs(ctx)
 	job := &store.Job{
-		OrgID:          req.OrgId,
-		AppVersionID:   req.AppVersionId,
-		TestPath:       req.TestPath,
-		Priority:       req.Priority,
-		Target:         targetToString(req.Target),
-		Status:         "PENDING",
-		IdempotencyKey: &req.IdempotencyKey,
-		WebAppURL:      &req.WebAppUrl,
-		TestType:       testTypeToString(req.TestType),
-	}
-
-	if err := s.postgresStore.CreateJob(ctx, job); err != nil {
-		log.Printf("Failed to create job: %v", err)
+		OrgID:           req.OrgId,
+		AppVersionID:    req.AppVersionId,
+		TestPath:        req.TestPath,
+		Priority:        req.Priority,
+		Target:          targetToString(req.Target),
+		Status:          "SUBMITTED",
+		WebAppURL:       &req.WebAppUrl,
+		TestType:        &testType,
+		TraceID:         traceID,
+		SpanID:          spanID,
+		RequiredLabels:  req.RequiredLabels,
+		PreferredLabels: req.PreferredLabels,
+	}
+	// Leave the key NULL when absent; the column is unique
+	if req.IdempotencyKey != "" {
+		job.IdempotencyKey = &req.IdempotencyKey
+	}
+
+	if len(devices) > 0 {
+		return s.submitMatrix(ctx, req, job, devices)
+	}
+
+	if err := s.jobStore.CreateJob(ctx, job); err != nil {
+		// The unique key catches duplicates the idempotency cache missed,
+		// e.g. while Redis is down
+		if errors.Is(err, store.ErrDuplicate) {
+			return nil, status.Error(codes.AlreadyExists, "job with this idempotency key already exists")
+		}
+		slog.ErrorContext(ctx, "Failed to create job", logging.KeyOrgID, req.OrgId, "error", err)
 		return nil, status.Error(codes.Internal, "failed to create job")
 	}
+	metrics.JobsSubmitted.WithLabelValues(job.Target, job.OrgID).Inc()
+	ctx = logging.WithJob(ctx, job.ID.String(), job.OrgID)
 
 	// Set idempotency key if provided
 	if req.IdempotencyKey != "" {
-		if err := s.redisStore.SetIdempotency(ctx, req.IdempotencyKey, 24*time.Hour); err != nil {
-			log.Printf("Failed to set idempotency key: %v", err)
+		if err := s.cacheStore.SetIdempotency(ctx, req.IdempotencyKey, s.cache.IdempotencyTTL); err != nil {
+			slog.WarnContext(ctx, "Failed to set idempotency key", "error", err)
 		}
 	}
 
 	// Push to ingestion queue
-	if err := s.redisStore.PushToIngestionQueue(ctx, job.ID); err != nil {
-		log.Printf("Failed to push to ingestion queue: %v", err)
-		// Don't fail the request, just log the error
+	if err := s.queueStore.PushToIngestionQueue(ctx, job.ID); err != nil {
+		slog.WarnContext(ctx, "Failed to push to ingestion queue", "error", err)
+		// Don't fail the request; ingestion reconciliation picks the job up
 	}
 
-	log.Printf("Created job %s for org %s, app version %s", job.ID, req.OrgId, req.AppVersionId)
+	slog.InfoContext(ctx, "Created job", "app_version_id", req.AppVersionId, "target", job.Target,
+		"required_labels", job.RequiredLabels.String(), "preferred_labels", job.PreferredLabels.String())
 
 	return &pb.SubmitJobResponse{
 		JobId:  job.ID.String(),
@@ -90,6 +156,57 @@ func (s *JobService) SubmitJob(ctx context.Context, req *pb.SubmitJobRequest) (*
 	}, nil
 }
 
+// submitMatrix fans job out into one child job per device. The children
+// share a parent ID, which names the matrix in GetJobStatus; the
+// idempotency key, being unique, is kept on the first device's job.
+func (s *JobService) submitMatrix(ctx context.Context, req *pb.SubmitJobRequest, job *store.Job, devices []string) (*pb.SubmitJobResponse, error) {
+	parentID := uuid.New()
+	jobs := make([]*store.Job, len(devices))
+	for i, device := range devices {
+		child := *job
+		child.ParentJobID = &parentID
+		child.Device = &device
+		if i > 0 {
+			child.IdempotencyKey = nil
+		}
+		jobs[i] = &child
+	}
+
+	if err := s.jobStore.CreateJobs(ctx, jobs); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			return nil, status.Error(codes.AlreadyExists, "job with this idempotency key already exists")
+		}
+		slog.ErrorContext(ctx, "Failed to create device jobs", logging.KeyOrgID, req.OrgId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to create job")
+	}
+
+	if req.IdempotencyKey != "" {
+		if err := s.cacheStore.SetIdempotency(ctx, req.IdempotencyKey, s.cache.IdempotencyTTL); err != nil {
+			slog.WarnContext(ctx, "Failed to set idempotency key", "error", err)
+		}
+	}
+
+	response := &pb.SubmitJobResponse{
+		JobId:  parentID.String(),
+		Status: stringToStatus(job.Status),
+	}
+	for _, child := range jobs {
+		metrics.JobsSubmitted.WithLabelValues(child.Target, child.OrgID).Inc()
+		if err := s.queueStore.PushToIngestionQueue(ctx, child.ID); err != nil {
+			slog.WarnContext(logging.WithJob(ctx, child.ID.String(), child.OrgID), "Failed to push to ingestion queue", "error", err)
+		}
+		response.DeviceJobs = append(response.DeviceJobs, &pb.DeviceJob{
+			Device: *child.Device,
+			JobId:  child.ID.String(),
+			Status: stringToStatus(child.Status),
+		})
+	}
+
+	slog.InfoContext(ctx, "Created device matrix", "parent_job_id", parentID, "devices", len(devices),
+		"app_version_id", req.AppVersionId, "target", job.Target)
+	return response, nil
+}
+
 func (s *JobService) GetJobStatus(ctx context.Context, req *pb.GetJobStatusRequest) (*pb.GetJobStatusResponse, error) {
 	if req.JobId == "" {
 		return nil, status.Error(codes.InvalidArgument, "job_id is required")
@@ -100,37 +217,33 @@ func (s *JobService) GetJobStatus(ctx context.Context, req *pb.GetJobStatusReque
 		return nil, status.Error(codes.InvalidArgument, "invalid job_id format")
 	}
 
-	// Try to get from cache first
-	statusStr, err := s.redisStore.GetJobStatus(ctx, jobID)
-	if err == nil {
-		// Get job details from database for timestamp
-		job, err := s.postgresStore.GetJob(ctx, jobID)
-		if err == nil {
-			return &pb.GetJobStatusResponse{
-				JobId:      req.JobId,
-				Status:     stringToStatus(statusStr),
-				CreatedAt:  timestamppb.New(job.CreatedAt),
-			}, nil
-		}
-	}
-
-	// Get from database
-	job, err := s.postgresStore.GetJob(ctx, jobID)
+	job, err := s.jobStore.GetJob(ctx, jobID)
 	if err != nil {
+		// A device matrix has no job of its own, only its children
+		if errors.Is(err, store.ErrNotFound) {
+			children, err := s.jobStore.ListChildJobs(ctx, jobID)
+			if err == nil && len(children) > 0 {
+				return matrixStatusResponse(jobID, children), nil
+			}
+		}
 		return nil, status.Error(codes.NotFound, "job not found")
 	}
 
-	// Cache the status
-	if err := s.redisStore.SetJobStatus(ctx, jobID, job.Status, 5*time.Minute); err != nil {
-		log.Printf("Failed to cache job status: %v", err)
+	// Only the status comes from the cache; the rest of the response comes
+	// from the job either way
+	statusStr, err := s.cacheStore.GetJobStatus(ctx, jobID)
+	if err != nil {
+		statusStr = job.Status
+		if err := s.cacheStore.SetJobStatus(ctx, jobID, job.Status, s.cache.JobStatusTTL); err != nil {
+			slog.WarnContext(ctx, "Failed to cache job status", logging.KeyJobID, req.JobId, "error", err)
+		}
 	}
 
 	response := &pb.GetJobStatusResponse{
-		JobId:      job.ID.String(),
-		Status:     stringToStatus(job.Status),
-		CreatedAt:  timestamppb.New(job.CreatedAt),
+		JobId:     job.ID.String(),
+		Status:    stringToStatus(statusStr),
+		CreatedAt: timestamppb.New(job.CreatedAt),
 	}
-	
 	if job.CompletedAt != nil {
 		response.CompletedAt = timestamppb.New(*job.CompletedAt)
 	}
@@ -149,16 +262,89 @@ func (s *JobService) GetJobStatus(ctx context.Context, req *pb.GetJobStatusReque
 	if job.TestDuration != nil {
 		response.TestDuration = *job.TestDuration
 	}
-	
-	// Debug logging
-	log.Printf("Job %s: status=%s, session_id=%s, logs_url=%s, video_url=%s, test_duration=%d", 
-		job.ID, job.Status, 
-		func() string { if job.SessionID != nil { return *job.SessionID } else { return "nil" } }(),
-		func() string { if job.LogsURL != nil { return *job.LogsURL } else { return "nil" } }(),
-		func() string { if job.VideoURL != nil { return *job.VideoURL } else { return "nil" } }(),
-		func() int32 { if job.TestDuration != nil { return *job.TestDuration } else { return 0 } }(),
+	if job.TraceID != nil {
+		response.TraceId = *job.TraceID
+	}
+	if job.JobGroupID != nil {
+		response.JobGroupId = job.JobGroupID.String()
+	}
+	if job.ParentJobID != nil {
+		response.ParentJobId = job.ParentJobID.String()
+	}
+	if job.Device != nil {
+		response.Device = *job.Device
+	}
+
+	slog.DebugContext(logging.WithJob(ctx, req.JobId, job.OrgID), "Served job status",
+		"status", statusStr,
+		"session_id", response.SessionId,
+		"test_duration", response.TestDuration,
 	)
-	
+
+	return response, nil
+}
+
+func (s *JobService) GetJobGroup(ctx context.Context, req *pb.GetJobGroupRequest) (*pb.GetJobGroupResponse, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	groupID, err := uuid.Parse(req.GroupId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid group_id format")
+	}
+
+	group, err := s.jobStore.GetJobGroup(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "job group not found")
+		}
+		slog.ErrorContext(ctx, "Failed to get job group", logging.KeyGroupID, req.GroupId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to get job group")
+	}
+	jobs, err := s.jobStore.ListGroupJobs(ctx, groupID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list group jobs", logging.KeyGroupID, req.GroupId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to get job group")
+	}
+
+	response := &pb.GetJobGroupResponse{
+		GroupId:         group.ID.String(),
+		Status:          group.Status,
+		Target:          stringToTarget(group.Target),
+		AppVersionId:    group.AppVersionID,
+		CreatedAt:       timestamppb.New(group.CreatedAt),
+		RequiredLabels:  group.RequiredLabels,
+		PreferredLabels: group.PreferredLabels,
+	}
+	if group.WebAppURL != nil {
+		response.WebAppUrl = *group.WebAppURL
+	}
+	if group.TestType != nil {
+		response.TestType = stringToTestType(*group.TestType)
+	}
+	if group.Device != nil {
+		response.Device = *group.Device
+	}
+	if group.AgentID != nil {
+		response.AgentId = group.AgentID.String()
+	}
+	if group.CompletedAt != nil {
+		response.CompletedAt = timestamppb.New(*group.CompletedAt)
+	}
+	for _, job := range jobs {
+		groupJob := &pb.GroupJob{
+			JobId:    job.ID.String(),
+			Status:   stringToStatus(job.Status),
+			TestPath: job.TestPath,
+			Priority: job.Priority,
+		}
+		if job.ErrorMessage != nil {
+			groupJob.ErrorMessage = *job.ErrorMessage
+		}
+		response.Jobs = append(response.Jobs, groupJob)
+	}
+
 	return response, nil
 }
 
@@ -166,30 +352,114 @@ func (s *JobService) RegisterAgent(ctx context.Context, req *pb.RegisterAgentReq
 	if req.Hostname == "" || req.TargetCapability == "" {
 		return nil, status.Error(codes.InvalidArgument, "hostname and target_capability are required")
 	}
+	if err := validateLabels("labels", req.Labels); err != nil {
+		return nil, err
+	}
+	if _, err := maxParallelism(req.Labels); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "labels: %v", err)
+	}
+	// An agent may run several targets, e.g. "browserstack,web"
+	targets := (&store.Agent{TargetCapability: req.TargetCapability}).Targets()
+	for _, target := range targets {
+		if stringToTarget(target) == pb.Target_TARGET_UNSPECIFIED {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown target %q in target_capability", target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "hostname and target_capability are required")
+	}
 
 	agent := &store.Agent{
 		Hostname:         req.Hostname,
-		TargetCapability: req.TargetCapability,
-		Status:         "IDLE",
+		TargetCapability: strings.Join(targets, ","),
+		Status:           "IDLE",
+		Labels:           req.Labels,
+	}
+
+	// Hostnames are unique, so an agent restarting after a crash, or after
+	// deregistering, takes back its old row and ID. Two instances racing to
+	// create the same hostname's row both end up reusing the one created.
+	previous, err := s.agentStore.GetAgentByHostname(ctx, req.Hostname)
+	if errors.Is(err, store.ErrNotFound) {
+		previous = nil
+		err = s.agentStore.CreateAgent(ctx, agent)
+		if errors.Is(err, store.ErrDuplicate) {
+			previous, err = s.agentStore.GetAgentByHostname(ctx, req.Hostname)
+		}
 	}
-
-	if err := s.postgresStore.CreateAgent(ctx, agent); err != nil {
-		log.Printf("Failed to create agent: %v", err)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to register agent", "hostname", req.Hostname, "error", err)
 		return nil, status.Error(codes.Internal, "failed to register agent")
 	}
+	if previous != nil {
+		// Sessions are per instance, so this only catches a second agent
+		// connected to this server under the same hostname
+		if s.sessions.get(previous.ID) != nil {
+			return nil, status.Errorf(codes.AlreadyExists, "an agent registered as %s is already connected; give each agent on a host its own name", req.Hostname)
+		}
+		agent.ID = previous.ID
+		if err := s.agentStore.UpdateAgent(ctx, agent); err != nil {
+			slog.ErrorContext(ctx, "Failed to register agent", "hostname", req.Hostname, "error", err)
+			return nil, status.Error(codes.Internal, "failed to register agent")
+		}
+	}
+
+	ctx = logging.WithAgent(ctx, agent.ID.String())
+
+	// Whatever the agent held before it restarted won't be finished now
+	if previous != nil {
+		requeued, err := handBackJobs(ctx, s.jobStore, s.cacheStore, s.cache.JobStatusTTL, s.sessions, agent.ID, "agent re-registered")
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to requeue re-registered agent's jobs", "error", err)
+		} else if requeued > 0 {
+			slog.InfoContext(ctx, "Requeued jobs of re-registered agent", "requeued_jobs", requeued)
+		}
+	}
 
 	// Set initial heartbeat
-	if err := s.redisStore.UpdateAgentHeartbeat(ctx, agent.ID, 2*time.Minute); err != nil {
-		log.Printf("Failed to set initial heartbeat: %v", err)
+	if err := s.cacheStore.UpdateAgentHeartbeat(ctx, agent.ID, s.cache.HeartbeatTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to set initial heartbeat", "error", err)
 	}
 
-	log.Printf("Registered agent %s with capability %s", agent.ID, req.TargetCapability)
+	slog.InfoContext(ctx, "Registered agent", "hostname", req.Hostname, "target_capability", req.TargetCapability, "labels", agent.Labels.String(), "reused", previous != nil)
 
 	return &pb.RegisterAgentResponse{
 		AgentId: agent.ID.String(),
 	}, nil
 }
 
+// DeregisterAgent takes an agent OFFLINE so that it no longer shows as
+// available once it has shut down. Jobs it still holds are requeued, so an
+// agent that gave up waiting for them hands them back.
+func (s *JobService) DeregisterAgent(ctx context.Context, req *pb.DeregisterAgentRequest) (*pb.DeregisterAgentResponse, error) {
+	agentID, err := uuid.Parse(req.AgentId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid agent_id format")
+	}
+	ctx = logging.WithAgent(ctx, req.AgentId)
+
+	if err := s.agentStore.UpdateAgentStatus(ctx, agentID, "OFFLINE"); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "agent not found")
+		}
+		slog.ErrorContext(ctx, "Failed to deregister agent", "error", err)
+		return nil, status.Error(codes.Internal, "failed to deregister agent")
+	}
+
+	if err := s.cacheStore.RemoveAgentHeartbeat(ctx, agentID); err != nil {
+		slog.WarnContext(ctx, "Failed to remove agent heartbeat", "error", err)
+	}
+
+	requeued, err := handBackJobs(ctx, s.jobStore, s.cacheStore, s.cache.JobStatusTTL, s.sessions, agentID, "agent deregistered")
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to requeue deregistered agent's jobs", "error", err)
+		return nil, status.Error(codes.Internal, "agent deregistered but its jobs could not be requeued")
+	}
+
+	slog.InfoContext(ctx, "Deregistered agent", "requeued_jobs", requeued)
+	return &pb.DeregisterAgentResponse{AgentId: req.AgentId, Status: "OFFLINE", RequeuedJobs: int32(requeued)}, nil
+}
+
 func (s *JobService) UpdateJobStatus(ctx context.Context, req *pb.UpdateJobStatusRequest) (*pb.UpdateJobStatusResponse, error) {
 	if req.JobId == "" {
 		return nil, status.Error(codes.InvalidArgument, "job_id is required")
@@ -200,33 +470,65 @@ func (s *JobService) UpdateJobStatus(ctx context.Context, req *pb.UpdateJobStatu
 		return nil, status.Error(codes.InvalidArgument, "invalid job_id format")
 	}
 
+	ctx = logging.WithJob(ctx, req.JobId, "")
+	if req.AgentId != "" {
+		ctx = logging.WithAgent(ctx, req.AgentId)
+	}
+
+	if err := s.applyJobStatus(ctx, jobID, statusToString(req.Status), req.AgentId, nil); err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateJobStatusResponse{
+		Success: true,
+	}, nil
+}
+
+// applyJobStatus records a status reported by an agent, whether through
+// UpdateJobStatus or its session stream. A non-nil result is stored with
+// the status.
+func (s *JobService) applyJobStatus(ctx context.Context, jobID uuid.UUID, statusStr, agentIDStr string, result *store.JobResult) error {
+	// Load the job before the transition so it can be measured
+	var previous *store.Job
+	var err error
+	if statusStr == "RUNNING" || statusStr == "COMPLETED" || statusStr == "FAILED" {
+		previous, err = s.jobStore.GetJob(ctx, jobID)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to load job for metrics", "error", err)
+		}
+	}
+
 	// Update job status
-	statusStr := statusToString(req.Status)
-	if err := s.postgresStore.UpdateJobStatus(ctx, jobID, statusStr); err != nil {
-		log.Printf("Failed to update job status: %v", err)
-		return nil, status.Error(codes.Internal, "failed to update job status")
+	if result != nil {
+		err = s.jobStore.UpdateJobResult(ctx, jobID, result)
+	} else {
+		err = s.jobStore.UpdateJobStatus(ctx, jobID, statusStr)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update job status", "status", statusStr, "error", err)
+		return status.Error(codes.Internal, "failed to update job status")
+	}
+	if previous != nil {
+		recordTransition(previous, statusStr)
 	}
 
 	// Update cache
-	if err := s.redisStore.SetJobStatus(ctx, jobID, statusStr, 5*time.Minute); err != nil {
-		log.Printf("Failed to update job status cache: %v", err)
+	if err := s.cacheStore.SetJobStatus(ctx, jobID, statusStr, s.cache.JobStatusTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to update job status cache", "error", err)
 	}
 
 	// Update agent heartbeat if provided
-	if req.AgentId != "" {
-		agentID, err := uuid.Parse(req.AgentId)
+	if agentIDStr != "" {
+		agentID, err := uuid.Parse(agentIDStr)
 		if err == nil {
-			if err := s.redisStore.UpdateAgentHeartbeat(ctx, agentID, 2*time.Minute); err != nil {
-				log.Printf("Failed to update agent heartbeat: %v", err)
+			if err := s.cacheStore.UpdateAgentHeartbeat(ctx, agentID, s.cache.HeartbeatTTL); err != nil {
+				slog.WarnContext(ctx, "Failed to update agent heartbeat", "error", err)
 			}
 		}
 	}
 
-	log.Printf("Updated job %s status to %s", req.JobId, statusStr)
-
-	return &pb.UpdateJobStatusResponse{
-		Success: true,
-	}, nil
+	slog.InfoContext(ctx, "Updated job status", "status", statusStr)
+	return nil
 }
 
 func (s *JobService) FetchJob(ctx context.Context, req *pb.FetchJobRequest) (*pb.FetchJobResponse, error) {
@@ -234,19 +536,43 @@ func (s *JobService) FetchJob(ctx context.Context, req *pb.FetchJobRequest) (*pb
 		return nil, status.Error(codes.InvalidArgument, "target_capability is required")
 	}
 
-	// For simplicity, this example fetches the first available job.
-	// A real implementation would have more sophisticated logic.
-	job, err := s.postgresStore.GetNextJob(ctx, req.TargetCapability)
-	if err != nil {
-		log.Printf("Failed to get next job: %v", err)
-		return nil, status.Error(codes.Internal, "failed to get next job")
+	// Draining and evicted agents get no new work. Anonymous agents have
+	// no labels, so they only get jobs without required labels.
+	var agentID uuid.UUID
+	var labels store.Labels
+	if req.AgentId != "" {
+		if id, err := uuid.Parse(req.AgentId); err == nil {
+			agent, err := s.agentStore.GetAgent(ctx, id)
+			if err == nil && (agent.Status == "DRAINING" || agent.Status == "OFFLINE") {
+				return nil, status.Errorf(codes.FailedPrecondition, "agent is %s", agent.Status)
+			}
+			if err == nil {
+				agentID, labels = id, agent.Labels
+			}
+		}
 	}
 
-	if job == nil {
-		return nil, status.Error(codes.NotFound, "no jobs available")
+	job, err := s.nextJob(ctx, req.TargetCapability, agentID, labels, fetchWait(req.WaitSeconds))
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.FetchJobResponse{
+	return dispatch(ctx, job), nil
+}
+
+// dispatch records the hand-off of a claimed job in the job's own trace and
+// builds the assignment passed on to the agent.
+func dispatch(ctx context.Context, job *store.Job) *pb.FetchJobResponse {
+	jobCtx, span := tracing.Tracer("server").Start(tracing.JobContext(ctx, job.TraceID, job.SpanID), "JobService.FetchJob.dispatch")
+	span.SetAttributes(
+		attribute.String("job.id", job.ID.String()),
+		attribute.String("job.target", job.Target),
+	)
+	defer span.End()
+
+	slog.InfoContext(logging.WithJob(jobCtx, job.ID.String(), job.OrgID), "Dispatched job", "target", job.Target)
+
+	response := &pb.FetchJobResponse{
 		JobId:          job.ID.String(),
 		OrgId:          job.OrgID,
 		AppVersionId:   job.AppVersionID,
@@ -255,7 +581,308 @@ func (s *JobService) FetchJob(ctx context.Context, req *pb.FetchJobRequest) (*pb
 		Target:         stringToTarget(job.Target),
 		WebAppUrl:      *job.WebAppURL,
 		TestType:       stringToTestType(*job.TestType),
-	}, nil
+		TraceParent:    tracing.TraceParent(jobCtx),
+	}
+	if job.Device != nil {
+		response.Device = *job.Device
+	}
+	return response
+}
+
+// dispatchGroup builds the assignment for a claimed group, recording the
+// hand-off of each of its jobs in the job's own trace.
+func dispatchGroup(ctx context.Context, group *store.JobGroup, jobs []*store.Job) *pb.GroupAssignment {
+	assignment := &pb.GroupAssignment{
+		GroupId:      group.ID.String(),
+		AppVersionId: group.AppVersionID,
+		Target:       stringToTarget(group.Target),
+	}
+	if group.WebAppURL != nil {
+		assignment.WebAppUrl = *group.WebAppURL
+	}
+	if group.TestType != nil {
+		assignment.TestType = stringToTestType(*group.TestType)
+	}
+	if group.Device != nil {
+		assignment.Device = *group.Device
+	}
+	for _, job := range jobs {
+		assignment.Jobs = append(assignment.Jobs, dispatch(ctx, job))
+	}
+
+	slog.InfoContext(logging.WithGroup(ctx, group.ID.String()), "Dispatched job group", "target", group.Target, "jobs", len(jobs))
+	return assignment
+}
+
+// fetchWait bounds how long FetchJob blocks on the dispatch queue.
+func fetchWait(seconds int32) time.Duration {
+	wait := time.Duration(seconds) * time.Second
+	if wait < minFetchWait {
+		return minFetchWait
+	}
+	if wait > maxFetchWait {
+		return maxFetchWait
+	}
+	return wait
+}
+
+// nextJob pops groups off the target's dispatch queue until it claims a job
+// from one the agent's labels match, or wait runs out. A group with jobs
+// left goes back on the queue for the next fetch; entries for groups with
+// nothing left are dropped.
+func (s *JobService) nextJob(ctx context.Context, target string, agentID uuid.UUID, labels store.Labels, wait time.Duration) (*store.Job, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		group, err := s.popMatchingGroup(ctx, target, agentID, labels, deadline)
+		if err != nil {
+			return nil, err
+		}
+		groupID := group.ID
+
+		// Claim the next job so no other agent is handed the same one
+		job, more, err := s.jobStore.ClaimGroupJob(ctx, groupID)
+		if err != nil {
+			slog.ErrorContext(logging.WithGroup(ctx, groupID.String()), "Failed to claim group job", "target", target, "error", err)
+			s.requeueGroup(ctx, target, groupID)
+			return nil, status.Error(codes.Internal, "failed to get next job")
+		}
+		if more {
+			s.requeueGroup(ctx, target, groupID)
+		}
+		if job != nil {
+			return job, nil
+		}
+		slog.DebugContext(logging.WithGroup(ctx, groupID.String()), "Dropped dispatch entry for group with no waiting jobs", "target", target)
+	}
+}
+
+// nextGroup pops groups off the target's dispatch queue until it claims a
+// whole group the session's labels match, or wait runs out. A claimed
+// group holds a slot reserved on the session. Nothing is left in a claimed
+// group for anyone else, so its entry is not pushed back; entries for
+// groups already assigned or with nothing left are dropped. A group popped
+// once the session's slots are taken, e.g. by another of its targets or a
+// drain, goes back on the queue.
+func (s *JobService) nextGroup(ctx context.Context, session *agentSession, target string, wait time.Duration) (*store.JobGroup, []*store.Job, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		candidate, err := s.popMatchingGroup(ctx, target, session.agentID, session.labels, deadline)
+		if err != nil {
+			return nil, nil, err
+		}
+		groupID := candidate.ID
+
+		if !session.reserve() {
+			s.requeueGroup(ctx, target, groupID)
+			return nil, nil, status.Error(codes.ResourceExhausted, "no free slot")
+		}
+		group, jobs, err := s.jobStore.ClaimGroup(ctx, groupID, session.agentID)
+		if err != nil {
+			session.unreserve()
+			slog.ErrorContext(logging.WithGroup(ctx, groupID.String()), "Failed to claim job group", "target", target, "error", err)
+			s.requeueGroup(ctx, target, groupID)
+			return nil, nil, status.Error(codes.Internal, "failed to get next job group")
+		}
+		if group != nil {
+			return group, jobs, nil
+		}
+		session.unreserve()
+		slog.DebugContext(logging.WithGroup(ctx, groupID.String()), "Dropped dispatch entry for group with no waiting jobs", "target", target)
+	}
+}
+
+// popMatchingGroup pops groups off the target's dispatch queue until it
+// finds one an agent with the given labels should run. A group goes back
+// on the queue when the agent lacks one of its required labels, or lacks a
+// preferred one that another connected agent with a free slot has. Once
+// every waiting group has been passed over, it pauses before looking again
+// so an agent that matches nothing doesn't spin on the queue.
+func (s *JobService) popMatchingGroup(ctx context.Context, target string, agentID uuid.UUID, labels store.Labels, deadline time.Time) (*store.JobGroup, error) {
+	passed := make(map[uuid.UUID]bool)
+	for {
+		groupID, err := s.popGroup(ctx, target, deadline)
+		if err != nil {
+			return nil, err
+		}
+		groupCtx := logging.WithGroup(ctx, groupID.String())
+
+		if passed[groupID] {
+			s.requeueGroup(ctx, target, groupID)
+			select {
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			case <-time.After(min(rescanDelay, time.Until(deadline))):
+			}
+			passed = make(map[uuid.UUID]bool)
+			continue
+		}
+
+		group, err := s.jobStore.GetJobGroup(ctx, groupID)
+		if errors.Is(err, store.ErrNotFound) {
+			slog.WarnContext(groupCtx, "Dropped dispatch entry for unknown group", "target", target)
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(groupCtx, "Failed to load job group", "target", target, "error", err)
+			s.requeueGroup(ctx, target, groupID)
+			return nil, status.Error(codes.Internal, "failed to get next job")
+		}
+
+		if !labels.Matches(group.RequiredLabels) {
+			slog.DebugContext(groupCtx, "Passed over group the agent lacks required labels for", "required_labels", group.RequiredLabels.String())
+		} else if !labels.Matches(group.PreferredLabels) && s.sessions.preferredElsewhere(agentID, group) {
+			slog.DebugContext(groupCtx, "Passed over group for an agent with its preferred labels", "preferred_labels", group.PreferredLabels.String())
+		} else {
+			return group, nil
+		}
+		passed[groupID] = true
+		s.requeueGroup(ctx, target, groupID)
+	}
+}
+
+// popGroup pops the next group ID off the target's dispatch queue, waiting
+// until deadline for one.
+func (s *JobService) popGroup(ctx context.Context, target string, deadline time.Time) (uuid.UUID, error) {
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return uuid.Nil, status.Error(codes.NotFound, "no jobs available")
+	}
+
+	groupID, err := s.queueStore.PopFromDispatchQueue(ctx, target, remaining)
+	if errors.Is(err, store.ErrQueueEmpty) {
+		return uuid.Nil, status.Error(codes.NotFound, "no jobs available")
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return uuid.Nil, status.FromContextError(ctx.Err()).Err()
+		}
+		slog.ErrorContext(ctx, "Failed to pop dispatch queue", "target", target, "error", err)
+		return uuid.Nil, status.Error(codes.Internal, "failed to get next job")
+	}
+	return groupID, nil
+}
+
+func (s *JobService) requeueGroup(ctx context.Context, target string, groupID uuid.UUID) {
+	if err := s.queueStore.PushToDispatchQueue(context.WithoutCancel(ctx), target, groupID); err != nil {
+		// Reconciliation picks the group up again
+		slog.WarnContext(logging.WithGroup(ctx, groupID.String()), "Failed to requeue job group", "target", target, "error", err)
+	}
+}
+
+// parseDevices normalizes a device matrix, each entry "name:os_version"
+// such as "Pixel 7:13", rejecting malformed or repeated devices.
+func parseDevices(entries []string) ([]string, error) {
+	if len(entries) > maxDevices {
+		return nil, status.Errorf(codes.InvalidArgument, "devices: at most %d devices are allowed", maxDevices)
+	}
+	devices := make([]string, 0, len(entries))
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		name, version, ok := strings.Cut(entry, ":")
+		name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+		if !ok || name == "" || version == "" || strings.Contains(version, ":") {
+			return nil, status.Errorf(codes.InvalidArgument, "devices: %q must be name:os_version, e.g. \"Pixel 7:13\"", entry)
+		}
+		device := name + ":" + version
+		if seen[device] {
+			return nil, status.Errorf(codes.InvalidArgument, "devices: %q is listed twice", device)
+		}
+		seen[device] = true
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// matrixStatusResponse reports a device matrix by its child jobs. Its
+// status is COMPLETED once every device passed and FAILED once all have
+// finished with any failure. Until then it is the status the children
+// share, or RUNNING once any of them has been handed to an agent.
+func matrixStatusResponse(parentID uuid.UUID, children []*store.Job) *pb.GetJobStatusResponse {
+	response := &pb.GetJobStatusResponse{
+		JobId:     parentID.String(),
+		CreatedAt: timestamppb.New(children[0].CreatedAt),
+	}
+	if children[0].TraceID != nil {
+		response.TraceId = *children[0].TraceID
+	}
+
+	counts := make(map[string]int)
+	var completedAt *time.Time
+	for _, child := range children {
+		counts[child.Status]++
+		if child.CompletedAt != nil && (completedAt == nil || child.CompletedAt.After(*completedAt)) {
+			completedAt = child.CompletedAt
+		}
+
+		deviceJob := &pb.DeviceJob{
+			Device: *child.Device,
+			JobId:  child.ID.String(),
+			Status: stringToStatus(child.Status),
+		}
+		if child.ErrorMessage != nil {
+			deviceJob.ErrorMessage = *child.ErrorMessage
+		}
+		if child.SessionID != nil {
+			deviceJob.SessionId = *child.SessionID
+		}
+		if child.LogsURL != nil {
+			deviceJob.LogsUrl = *child.LogsURL
+		}
+		if child.VideoURL != nil {
+			deviceJob.VideoUrl = *child.VideoURL
+		}
+		if child.TestDuration != nil {
+			deviceJob.TestDuration = *child.TestDuration
+		}
+		response.DeviceJobs = append(response.DeviceJobs, deviceJob)
+	}
+
+	total, finished := len(children), counts["COMPLETED"]+counts["FAILED"]
+	switch {
+	case counts["COMPLETED"] == total:
+		response.Status = pb.Status_COMPLETED
+	case finished == total:
+		response.Status = pb.Status_FAILED
+	case len(counts) == 1:
+		response.Status = stringToStatus(children[0].Status)
+	case finished > 0 || counts["ASSIGNED"] > 0 || counts["RUNNING"] > 0:
+		response.Status = pb.Status_RUNNING
+	default:
+		response.Status = pb.Status_PENDING
+	}
+	if finished == total && completedAt != nil {
+		response.CompletedAt = timestamppb.New(*completedAt)
+	}
+	return response
+}
+
+// validateLabels rejects empty label keys and oversized keys or values.
+func validateLabels(field string, labels map[string]string) error {
+	for key, value := range labels {
+		if key == "" || len(key) > maxLabelLength || len(value) > maxLabelLength {
+			return status.Errorf(codes.InvalidArgument, "%s: keys must be 1-%d characters and values at most %d", field, maxLabelLength, maxLabelLength)
+		}
+	}
+	return nil
+}
+
+// recordTransition updates job lifecycle metrics for a status change. The
+// job's updated_at is taken as the start of its previous state.
+func recordTransition(job *store.Job, newStatus string) {
+	switch newStatus {
+	case "RUNNING":
+		metrics.JobQueueWait.WithLabelValues(job.Target).Observe(time.Since(job.CreatedAt).Seconds())
+	case "COMPLETED":
+		metrics.JobsCompleted.WithLabelValues(job.Target, job.OrgID).Inc()
+		if job.Status == "RUNNING" {
+			metrics.JobRunDuration.WithLabelValues(job.Target, newStatus).Observe(time.Since(job.UpdatedAt).Seconds())
+		}
+	case "FAILED":
+		metrics.JobsFailed.WithLabelValues(job.Target, job.OrgID).Inc()
+		if job.Status == "RUNNING" {
+			metrics.JobRunDuration.WithLabelValues(job.Target, newStatus).Observe(time.Since(job.UpdatedAt).Seconds())
+		}
+	}
 }
 
 // Helper functions for converting between protobuf and string representations
@@ -276,6 +903,8 @@ func targetToString(target pb.Target) string {
 
 func stringToStatus(status string) pb.Status {
 	switch status {
+	case "SUBMITTED":
+		return pb.Status_SUBMITTED
 	case "PENDING":
 		return pb.Status_PENDING
 	case "SCHEDULED":
@@ -297,6 +926,8 @@ func stringToStatus(status string) pb.Status {
 
 func statusToString(status pb.Status) string {
 	switch status {
+	case pb.Status_SUBMITTED:
+		return "SUBMITTED"
 	case pb.Status_PENDING:
 		return "PENDING"
 	case pb.Status_SCHEDULED:
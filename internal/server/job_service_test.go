@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "qualgent-test-platform/api/proto"
+	"qualgent-test-platform/internal/config"
+	"qualgent-test-platform/internal/ingest"
+	"qualgent-test-platform/internal/scheduler"
+	"qualgent-test-platform/internal/store"
+)
+
+// TestJobLifecycle runs one job through the server as in --dev mode:
+// submitted, ingested, scheduled into a group, fetched by an agent and
+// reported COMPLETED.
+func TestJobLifecycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cfg := config.DefaultServer()
+	cfg.Scheduler.Interval = 10 * time.Millisecond
+
+	jobStore, cacheStore := store.NewMemoryStore(), store.NewMemoryCache()
+	sched := scheduler.NewScheduler(jobStore, cacheStore, cacheStore, "test", cfg.Scheduler)
+	ingester := ingest.NewWorker(jobStore, cacheStore, cacheStore, ingest.DefaultSteps(cfg.Ingest), cfg.Ingest, cfg.Cache)
+	service := NewJobService(jobStore, jobStore, cacheStore, cacheStore, NewSessions(), cfg.Cache)
+
+	ingester.Start(ctx)
+	defer ingester.Stop()
+	sched.Start(ctx)
+	defer sched.Stop()
+
+	submitted, err := service.SubmitJob(ctx, &pb.SubmitJobRequest{
+		OrgId:        "qualgent",
+		AppVersionId: "bs://app",
+		TestPath:     "tests/onboarding.spec.js",
+		Target:       pb.Target_EMULATOR,
+	})
+	if err != nil {
+		t.Fatalf("SubmitJob: %v", err)
+	}
+
+	agent, err := service.RegisterAgent(ctx, &pb.RegisterAgentRequest{Hostname: "agent-1", TargetCapability: "emulator"})
+	if err != nil {
+		t.Fatalf("RegisterAgent: %v", err)
+	}
+
+	fetched, err := service.FetchJob(ctx, &pb.FetchJobRequest{AgentId: agent.AgentId, TargetCapability: "emulator", WaitSeconds: 10})
+	if err != nil {
+		t.Fatalf("FetchJob: %v", err)
+	}
+	if fetched.JobId != submitted.JobId {
+		t.Fatalf("FetchJob returned job %s, want %s", fetched.JobId, submitted.JobId)
+	}
+
+	for _, s := range []pb.Status{pb.Status_RUNNING, pb.Status_COMPLETED} {
+		if _, err := service.UpdateJobStatus(ctx, &pb.UpdateJobStatusRequest{JobId: fetched.JobId, Status: s, AgentId: agent.AgentId}); err != nil {
+			t.Fatalf("UpdateJobStatus(%s): %v", s, err)
+		}
+	}
+
+	got, err := service.GetJobStatus(ctx, &pb.GetJobStatusRequest{JobId: submitted.JobId})
+	if err != nil {
+		t.Fatalf("GetJobStatus: %v", err)
+	}
+	if got.Status != pb.Status_COMPLETED {
+		t.Errorf("job status = %s, want COMPLETED", got.Status)
+	}
+}
+
+func TestRegisterAgentReusesHostname(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultServer()
+	jobStore, cacheStore := store.NewMemoryStore(), store.NewMemoryCache()
+	service := NewJobService(jobStore, jobStore, cacheStore, cacheStore, NewSessions(), cfg.Cache)
+
+	register := func(hostname string) string {
+		t.Helper()
+		resp, err := service.RegisterAgent(ctx, &pb.RegisterAgentRequest{Hostname: hostname, TargetCapability: "web"})
+		if err != nil {
+			t.Fatalf("RegisterAgent(%s): %v", hostname, err)
+		}
+		return resp.AgentId
+	}
+
+	first := register("host-1")
+	if again := register("host-1"); again != first {
+		t.Errorf("re-registering host-1 gave agent %s, want %s", again, first)
+	}
+	if named := register("host-1/browserstack"); named == first {
+		t.Errorf("host-1/browserstack reused host-1's agent %s", first)
+	}
+}
+
+func TestGetJobStatusFromCacheKeepsJobDetails(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultServer()
+	jobStore, cacheStore := store.NewMemoryStore(), store.NewMemoryCache()
+	service := NewJobService(jobStore, jobStore, cacheStore, cacheStore, NewSessions(), cfg.Cache)
+
+	traceID, sessionID := "trace-1", "session-1"
+	job := &store.Job{OrgID: "qualgent", AppVersionID: "bs://app", TestPath: "tests/login.spec.js", Target: "emulator", Status: "RUNNING", TraceID: &traceID}
+	if err := jobStore.CreateJob(ctx, job); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	if err := jobStore.UpdateJobResult(ctx, job.ID, &store.JobResult{Status: "COMPLETED", SessionID: &sessionID}); err != nil {
+		t.Fatalf("UpdateJobResult: %v", err)
+	}
+	if err := cacheStore.SetJobStatus(ctx, job.ID, "COMPLETED", cfg.Cache.JobStatusTTL); err != nil {
+		t.Fatalf("SetJobStatus: %v", err)
+	}
+
+	got, err := service.GetJobStatus(ctx, &pb.GetJobStatusRequest{JobId: job.ID.String()})
+	if err != nil {
+		t.Fatalf("GetJobStatus: %v", err)
+	}
+	if got.Status != pb.Status_COMPLETED || got.TraceId != traceID || got.SessionId != sessionID || got.CompletedAt == nil {
+		t.Errorf("GetJobStatus = %v, want COMPLETED with the job's trace, session and completion time", got)
+	}
+}
@@ -0,0 +1,423 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// FailoverCache is the QueueStore and CacheStore the job server runs on. It
+// uses Redis while Redis is reachable and keeps the server working while it
+// isn't:
+//
+//   - scheduler locks fall back to the job store's Locker (Postgres advisory
+//     locks), so instances stay mutually exclusive;
+//   - idempotency checks are skipped, leaving the job store's unique
+//     idempotency key to reject duplicates;
+//   - job status is never cached, so reads go straight to the job store;
+//   - queues, heartbeats and the scheduler pause flag are kept in process,
+//     so each instance has its own dispatch queues; see Degraded.
+//
+// A background check reconnects once Redis answers again, moving any jobs
+// queued locally in the meantime onto the Redis queues.
+type FailoverCache struct {
+	redis    *RedisStore
+	local    *MemoryCache
+	locker   Locker
+	interval time.Duration
+
+	degraded atomic.Bool
+
+	mu sync.Mutex
+	// Keys of scheduler locks taken from locker rather than Redis
+	lockerLocks map[string]bool
+	// Jobs whose status changed while degraded, so Redis may hold a
+	// stale copy
+	staleStatuses map[uuid.UUID]bool
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewFailoverCache connects to Redis at addr, starting in degraded mode if
+// it can't be reached, and probes it every interval.
+func NewFailoverCache(addr string, locker Locker, interval time.Duration) *FailoverCache {
+	c := &FailoverCache{
+		redis:         &RedisStore{client: redis.NewClient(&redis.Options{Addr: addr})},
+		local:         NewMemoryCache(),
+		locker:        locker,
+		interval:      interval,
+		lockerLocks:   make(map[string]bool),
+		staleStatuses: make(map[uuid.UUID]bool),
+		stopChan:      make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.redis.Ping(ctx); err != nil {
+		c.degraded.Store(true)
+		slog.Warn("Redis unavailable, starting in degraded mode", "addr", addr, "error", err)
+	}
+	return c
+}
+
+// Start probes Redis in the background until ctx is done or Close.
+func (c *FailoverCache) Start(ctx context.Context) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stopChan:
+				return
+			case <-ticker.C:
+				c.probe(ctx)
+			}
+		}
+	}()
+}
+
+func (c *FailoverCache) Close() error {
+	close(c.stopChan)
+	c.wg.Wait()
+	return c.redis.Close()
+}
+
+// Degraded reports whether Redis is currently bypassed. The scheduler
+// then fills every instance's dispatch queues from the job store, not just
+// the lock holder's.
+func (c *FailoverCache) Degraded() bool {
+	return c.degraded.Load()
+}
+
+// Ping reports Redis reachability, for health checks.
+func (c *FailoverCache) Ping(ctx context.Context) error {
+	return c.redis.Ping(ctx)
+}
+
+func (c *FailoverCache) probe(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	err := c.redis.Ping(pingCtx)
+	cancel()
+
+	if err != nil {
+		c.markDown(err)
+		return
+	}
+	if !c.degraded.Load() {
+		return
+	}
+
+	// Drop statuses cached before the outage that have since changed
+	c.mu.Lock()
+	stale := c.staleStatuses
+	c.staleStatuses = make(map[uuid.UUID]bool)
+	c.mu.Unlock()
+	for jobID := range stale {
+		if err := c.redis.deleteJobStatus(ctx, jobID); err != nil {
+			c.mu.Lock()
+			for jobID := range stale {
+				c.staleStatuses[jobID] = true
+			}
+			c.mu.Unlock()
+			slog.Warn("Failed to invalidate cached job statuses", "error", err)
+			return
+		}
+	}
+
+	// Hand locally queued work back to Redis before switching over
+	moved := 0
+	for queue, ids := range c.local.takeQueues() {
+		for i, id := range ids {
+			if err := c.redis.pushRaw(ctx, queue, id); err != nil {
+				// Keep what's left locally and try again next probe
+				for _, rest := range ids[i:] {
+					c.local.push(queue, rest)
+				}
+				slog.Warn("Failed to move local queue to Redis", "queue", queue, "error", err)
+				return
+			}
+			moved++
+		}
+	}
+
+	c.degraded.Store(false)
+	slog.Info("Redis reconnected, leaving degraded mode", "requeued", moved)
+}
+
+// markDown switches to degraded mode, logging only the transition.
+func (c *FailoverCache) markDown(err error) {
+	if !c.degraded.Swap(true) {
+		slog.Warn("Redis unavailable, running in degraded mode", "error", err)
+	}
+}
+
+// useRedis reports whether to try Redis for the next operation.
+func (c *FailoverCache) useRedis() bool {
+	return !c.degraded.Load()
+}
+
+// unavailable reports whether err means Redis couldn't be reached, as
+// opposed to a reply such as a missing key, and if so enters degraded mode.
+// A call that ran out of time, such as a blocking pop outlasting its
+// caller's deadline, says nothing about Redis and is returned as it is; a
+// Redis that hangs is caught by the probe.
+func (c *FailoverCache) unavailable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if !connectionError(err) {
+		return false
+	}
+	c.markDown(err)
+	return true
+}
+
+// connectionError reports whether err came from dialing Redis or losing the
+// connection to it, rather than from a reply or a timed-out read.
+func connectionError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, redis.ErrClosed) ||
+		errors.Is(err, net.ErrClosed) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "dial" || !opErr.Timeout())
+}
+
+// Queue operations
+func (c *FailoverCache) PushToIngestionQueue(ctx context.Context, jobID uuid.UUID) error {
+	if c.useRedis() {
+		if err := c.redis.PushToIngestionQueue(ctx, jobID); !c.unavailable(ctx, err) {
+			return err
+		}
+	}
+	return c.local.PushToIngestionQueue(ctx, jobID)
+}
+
+func (c *FailoverCache) PopFromIngestionQueue(ctx context.Context, timeout time.Duration) (uuid.UUID, error) {
+	if c.useRedis() {
+		if id, err := c.redis.PopFromIngestionQueue(ctx, timeout); !c.unavailable(ctx, err) {
+			return id, err
+		}
+	}
+	return c.local.PopFromIngestionQueue(ctx, timeout)
+}
+
+func (c *FailoverCache) ListIngestionQueue(ctx context.Context) ([]uuid.UUID, error) {
+	if c.useRedis() {
+		if ids, err := c.redis.ListIngestionQueue(ctx); !c.unavailable(ctx, err) {
+			return ids, err
+		}
+	}
+	return c.local.ListIngestionQueue(ctx)
+}
+
+func (c *FailoverCache) PushToDispatchQueue(ctx context.Context, target string, groupID uuid.UUID) error {
+	if c.useRedis() {
+		if err := c.redis.PushToDispatchQueue(ctx, target, groupID); !c.unavailable(ctx, err) {
+			return err
+		}
+	}
+	return c.local.PushToDispatchQueue(ctx, target, groupID)
+}
+
+func (c *FailoverCache) PopFromDispatchQueue(ctx context.Context, target string, timeout time.Duration) (uuid.UUID, error) {
+	if c.useRedis() {
+		if id, err := c.redis.PopFromDispatchQueue(ctx, target, timeout); !c.unavailable(ctx, err) {
+			return id, err
+		}
+	}
+	return c.local.PopFromDispatchQueue(ctx, target, timeout)
+}
+
+func (c *FailoverCache) ListDispatchQueue(ctx context.Context, target string) ([]uuid.UUID, error) {
+	if c.useRedis() {
+		if ids, err := c.redis.ListDispatchQueue(ctx, target); !c.unavailable(ctx, err) {
+			return ids, err
+		}
+	}
+	return c.local.ListDispatchQueue(ctx, target)
+}
+
+func (c *FailoverCache) GetIngestionQueueLength(ctx context.Context) (int64, error) {
+	if c.useRedis() {
+		if n, err := c.redis.GetIngestionQueueLength(ctx); !c.unavailable(ctx, err) {
+			return n, err
+		}
+	}
+	return c.local.GetIngestionQueueLength(ctx)
+}
+
+func (c *FailoverCache) GetDispatchQueueLength(ctx context.Context, target string) (int64, error) {
+	if c.useRedis() {
+		if n, err := c.redis.GetDispatchQueueLength(ctx, target); !c.unavailable(ctx, err) {
+			return n, err
+		}
+	}
+	return c.local.GetDispatchQueueLength(ctx, target)
+}
+
+// Distributed lock operations
+func (c *FailoverCache) AcquireLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error) {
+	if c.useRedis() {
+		if acquired, err := c.redis.AcquireLock(ctx, lockKey, owner, ttl); !c.unavailable(ctx, err) {
+			return acquired, err
+		}
+	}
+
+	acquired, err := c.locker.TryLock(ctx, lockKey)
+	if err != nil || !acquired {
+		return false, err
+	}
+	c.mu.Lock()
+	c.lockerLocks[lockKey] = true
+	c.mu.Unlock()
+	return true, nil
+}
+
+// RenewLock keeps a Redis lock alive. Locker locks don't expire, so they
+// stay held until released; a Redis lock that can't be renewed because
+// Redis is down counts as lost.
+func (c *FailoverCache) RenewLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	fromLocker := c.lockerLocks[lockKey]
+	c.mu.Unlock()
+
+	if fromLocker {
+		return true, nil
+	}
+	renewed, err := c.redis.RenewLock(ctx, lockKey, owner, ttl)
+	if c.unavailable(ctx, err) {
+		return false, nil
+	}
+	return renewed, err
+}
+
+// ReleaseLock releases the lock wherever it was taken, even if Redis came
+// back or went away in between.
+func (c *FailoverCache) ReleaseLock(ctx context.Context, lockKey, owner string) error {
+	c.mu.Lock()
+	fromLocker := c.lockerLocks[lockKey]
+	delete(c.lockerLocks, lockKey)
+	c.mu.Unlock()
+
+	if fromLocker {
+		return c.locker.Unlock(ctx, lockKey)
+	}
+	err := c.redis.ReleaseLock(ctx, lockKey, owner)
+	if c.unavailable(ctx, err) {
+		// The lock's TTL releases it once Redis is back
+		return nil
+	}
+	return err
+}
+
+// Scheduler control operations
+func (c *FailoverCache) SetSchedulerPaused(ctx context.Context, paused bool) error {
+	if c.useRedis() {
+		if err := c.redis.SetSchedulerPaused(ctx, paused); !c.unavailable(ctx, err) {
+			return err
+		}
+	}
+	return c.local.SetSchedulerPaused(ctx, paused)
+}
+
+func (c *FailoverCache) IsSchedulerPaused(ctx context.Context) (bool, error) {
+	if c.useRedis() {
+		if paused, err := c.redis.IsSchedulerPaused(ctx); !c.unavailable(ctx, err) {
+			return paused, err
+		}
+	}
+	return c.local.IsSchedulerPaused(ctx)
+}
+
+// Heartbeat operations
+func (c *FailoverCache) UpdateAgentHeartbeat(ctx context.Context, agentID uuid.UUID, ttl time.Duration) error {
+	if c.useRedis() {
+		if err := c.redis.UpdateAgentHeartbeat(ctx, agentID, ttl); !c.unavailable(ctx, err) {
+			return err
+		}
+	}
+	return c.local.UpdateAgentHeartbeat(ctx, agentID, ttl)
+}
+
+func (c *FailoverCache) RemoveAgentHeartbeat(ctx context.Context, agentID uuid.UUID) error {
+	if c.useRedis() {
+		if err := c.redis.RemoveAgentHeartbeat(ctx, agentID); !c.unavailable(ctx, err) {
+			return err
+		}
+	}
+	return c.local.RemoveAgentHeartbeat(ctx, agentID)
+}
+
+func (c *FailoverCache) IsAgentAlive(ctx context.Context, agentID uuid.UUID) (bool, error) {
+	if c.useRedis() {
+		if alive, err := c.redis.IsAgentAlive(ctx, agentID); !c.unavailable(ctx, err) {
+			return alive, err
+		}
+	}
+	return c.local.IsAgentAlive(ctx, agentID)
+}
+
+// Idempotency operations
+
+// CheckIdempotency reports false while degraded; the job store's unique
+// idempotency key then rejects the duplicate on insert.
+func (c *FailoverCache) CheckIdempotency(ctx context.Context, key string) (bool, error) {
+	if c.useRedis() {
+		if exists, err := c.redis.CheckIdempotency(ctx, key); !c.unavailable(ctx, err) {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+func (c *FailoverCache) SetIdempotency(ctx context.Context, key string, ttl time.Duration) error {
+	if c.useRedis() {
+		if err := c.redis.SetIdempotency(ctx, key, ttl); !c.unavailable(ctx, err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cache operations
+
+// SetJobStatus only notes the job while degraded, and GetJobStatus misses,
+// so status reads go to the job store.
+func (c *FailoverCache) SetJobStatus(ctx context.Context, jobID uuid.UUID, status string, ttl time.Duration) error {
+	if c.useRedis() {
+		if err := c.redis.SetJobStatus(ctx, jobID, status, ttl); !c.unavailable(ctx, err) {
+			return err
+		}
+	}
+	c.mu.Lock()
+	c.staleStatuses[jobID] = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *FailoverCache) GetJobStatus(ctx context.Context, jobID uuid.UUID) (string, error) {
+	if c.useRedis() {
+		if status, err := c.redis.GetJobStatus(ctx, jobID); !c.unavailable(ctx, err) {
+			return status, err
+		}
+	}
+	return "", fmt.Errorf("failed to get job status from cache: %w", ErrNotFound)
+}
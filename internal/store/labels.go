@@ -0,0 +1,69 @@
+package store
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Labels are the capabilities an agent advertises, such as os_version=13
+// or browser=chromium, or the constraints a job puts on the agent that
+// runs it. They are stored as a JSON object; empty labels are NULL.
+type Labels map[string]string
+
+// Matches reports whether l has every label in want with the same value.
+// Empty constraints match any agent.
+func (l Labels) Matches(want Labels) bool {
+	for key, value := range want {
+		if have, ok := l[key]; !ok || have != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the labels as sorted key=value pairs, so equal labels
+// give equal strings.
+func (l Labels) String() string {
+	pairs := make([]string, 0, len(l))
+	for key, value := range l {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (l Labels) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(map[string]string(l))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode labels: %w", err)
+	}
+	return string(data), nil
+}
+
+func (l *Labels) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan labels: unsupported type %T", src)
+	}
+
+	var labels map[string]string
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return fmt.Errorf("failed to decode labels: %w", err)
+	}
+	*l = labels
+	return nil
+}
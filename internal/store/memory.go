@@ -0,0 +1,889 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore is an in-process JobStore and AgentStore for development and
+// tests. It mirrors the PostgresStore semantics, including the unique
+// idempotency key and agent hostname constraints, but keeps nothing across
+// restarts.
+type MemoryStore struct {
+	mu     sync.Mutex
+	jobs   map[uuid.UUID]*Job
+	groups map[uuid.UUID]*JobGroup
+	agents map[uuid.UUID]*Agent
+	fences map[string]int64
+	// seq breaks created_at ties so listings are stable
+	seq map[uuid.UUID]int64
+	n   int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs:   make(map[uuid.UUID]*Job),
+		groups: make(map[uuid.UUID]*JobGroup),
+		agents: make(map[uuid.UUID]*Agent),
+		fences: make(map[string]int64),
+		seq:    make(map[uuid.UUID]int64),
+	}
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) nextSeq(id uuid.UUID) {
+	s.n++
+	s.seq[id] = s.n
+}
+
+// Job operations
+func (s *MemoryStore) CreateJob(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createJob(job)
+}
+
+// CreateJobs creates several jobs at once, such as the device jobs of a
+// matrix, so that either all of them exist or none do.
+func (s *MemoryStore) CreateJobs(ctx context.Context, jobs []*Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := make([]uuid.UUID, 0, len(jobs))
+	for _, job := range jobs {
+		if err := s.createJob(job); err != nil {
+			for _, id := range created {
+				delete(s.jobs, id)
+				delete(s.seq, id)
+			}
+			return err
+		}
+		created = append(created, job.ID)
+	}
+	return nil
+}
+
+// createJob stores a new job. Callers hold s.mu.
+func (s *MemoryStore) createJob(job *Job) error {
+	if job.IdempotencyKey != nil {
+		for _, existing := range s.jobs {
+			if existing.IdempotencyKey != nil && *existing.IdempotencyKey == *job.IdempotencyKey {
+				return fmt.Errorf("failed to create job: %w", ErrDuplicate)
+			}
+		}
+	}
+
+	now := time.Now()
+	job.ID = uuid.New()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if job.Status == "" {
+		job.Status = "PENDING"
+	}
+
+	stored := *job
+	s.jobs[job.ID] = &stored
+	s.nextSeq(job.ID)
+	return nil
+}
+
+func (s *MemoryStore) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get job: %w", ErrNotFound)
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (s *MemoryStore) UpdateJobStatus(ctx context.Context, id uuid.UUID, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[id]; ok {
+		job.Status = status
+		job.UpdatedAt = time.Now()
+		s.rollupGroup(job.JobGroupID)
+	}
+	return nil
+}
+
+func (s *MemoryStore) UpdateJobResult(ctx context.Context, id uuid.UUID, result *JobResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	job.Status = result.Status
+	job.SessionID = result.SessionID
+	job.LogsURL = result.LogsURL
+	job.VideoURL = result.VideoURL
+	job.ErrorMessage = result.ErrorMessage
+	job.TestDuration = result.TestDuration
+	job.CompletedAt = &now
+	job.UpdatedAt = now
+	s.rollupGroup(job.JobGroupID)
+	return nil
+}
+
+func (s *MemoryStore) GetPendingJobs(ctx context.Context, limit int) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.filterJobs(func(job *Job) bool { return job.Status == "PENDING" })
+	s.sortByPriority(jobs)
+	return limitJobs(jobs, limit), nil
+}
+
+func (s *MemoryStore) GetSubmittedJobs(ctx context.Context, limit int) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.filterJobs(func(job *Job) bool { return job.Status == "SUBMITTED" })
+	sort.Slice(jobs, func(i, j int) bool { return s.seq[jobs[i].ID] < s.seq[jobs[j].ID] })
+	return limitJobs(jobs, limit), nil
+}
+
+func (s *MemoryStore) AcceptJob(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.jobs[job.ID]
+	if !ok || stored.Status != "SUBMITTED" {
+		return fmt.Errorf("failed to accept job: %w", ErrNotFound)
+	}
+	stored.AppVersionID = job.AppVersionID
+	stored.TestPath = job.TestPath
+	stored.Priority = job.Priority
+	stored.Status = "PENDING"
+	stored.UpdatedAt = time.Now()
+	job.Status = "PENDING"
+	return nil
+}
+
+func (s *MemoryStore) ClaimGroupJob(ctx context.Context, groupID uuid.UUID) (*Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.filterJobs(func(job *Job) bool {
+		return job.Status == "SCHEDULED" && job.JobGroupID != nil && *job.JobGroupID == groupID
+	})
+	if len(jobs) == 0 {
+		return nil, false, nil // No job available
+	}
+	s.sortByPriority(jobs)
+
+	claimed := s.jobs[jobs[0].ID]
+	claimed.Status = "ASSIGNED"
+	claimed.UpdatedAt = time.Now()
+	s.rollupGroup(&groupID)
+	copied := *claimed
+	return &copied, len(jobs) > 1, nil
+}
+
+func (s *MemoryStore) ClaimGroup(ctx context.Context, groupID, agentID uuid.UUID) (*JobGroup, []*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[groupID]
+	if !ok || group.AgentID != nil {
+		return nil, nil, nil
+	}
+	waiting := s.filterJobs(func(job *Job) bool {
+		return job.Status == "SCHEDULED" && job.JobGroupID != nil && *job.JobGroupID == groupID
+	})
+	if len(waiting) == 0 {
+		return nil, nil, nil
+	}
+	s.sortByPriority(waiting)
+
+	now := time.Now()
+	group.AgentID = &agentID
+	group.UpdatedAt = now
+	jobs := make([]*Job, 0, len(waiting))
+	for _, job := range waiting {
+		claimed := s.jobs[job.ID]
+		claimed.Status = "ASSIGNED"
+		claimed.UpdatedAt = now
+		copied := *claimed
+		jobs = append(jobs, &copied)
+	}
+	s.rollupGroup(&groupID)
+	copied := *group
+	return &copied, jobs, nil
+}
+
+func (s *MemoryStore) ListJobs(ctx context.Context, limit int) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.filterJobs(func(*Job) bool { return true })
+	sort.Slice(jobs, func(i, j int) bool { return s.seq[jobs[i].ID] > s.seq[jobs[j].ID] })
+	return limitJobs(jobs, limit), nil
+}
+
+func (s *MemoryStore) CountJobsByStatus(ctx context.Context) (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int64)
+	for _, job := range s.jobs {
+		counts[job.Status]++
+	}
+	return counts, nil
+}
+
+// Admin operations
+func (s *MemoryStore) RequeueJob(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.requeueLocked(job)
+	return nil
+}
+
+// RequeueUnfinishedJob requeues a job unless it has already COMPLETED or
+// FAILED, reporting whether it did.
+func (s *MemoryStore) RequeueUnfinishedJob(ctx context.Context, id uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.Status == "COMPLETED" || job.Status == "FAILED" {
+		return false, nil
+	}
+	s.requeueLocked(job)
+	return true, nil
+}
+
+// requeueLocked resets job to PENDING. Callers hold s.mu.
+func (s *MemoryStore) requeueLocked(job *Job) {
+	groupID := job.JobGroupID
+	job.Status = "PENDING"
+	job.JobGroupID = nil
+	job.SessionID = nil
+	job.LogsURL = nil
+	job.VideoURL = nil
+	job.ErrorMessage = nil
+	job.TestDuration = nil
+	job.CompletedAt = nil
+	job.UpdatedAt = time.Now()
+	s.rollupGroup(groupID)
+}
+
+func (s *MemoryStore) RequeueAgentJobs(ctx context.Context, agentID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requeued int64
+	touched := make(map[uuid.UUID]bool)
+	for _, job := range s.jobs {
+		if job.JobGroupID == nil {
+			continue
+		}
+		group, ok := s.groups[*job.JobGroupID]
+		if !ok || group.AgentID == nil || *group.AgentID != agentID {
+			continue
+		}
+		switch job.Status {
+		case "SCHEDULED", "ASSIGNED", "RUNNING":
+			touched[group.ID] = true
+			job.Status = "PENDING"
+			job.JobGroupID = nil
+			job.UpdatedAt = time.Now()
+			requeued++
+		}
+	}
+	for groupID := range touched {
+		s.rollupGroup(&groupID)
+	}
+	return requeued, nil
+}
+
+// JobGroup operations
+func (s *MemoryStore) CreateJobGroup(ctx context.Context, group *JobGroup, jobIDs []uuid.UUID, fence Fence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fences[fence.Key] != fence.Token {
+		return fmt.Errorf("failed to create job group: %w", ErrFenced)
+	}
+
+	var pending []*Job
+	for _, id := range jobIDs {
+		if job, ok := s.jobs[id]; ok && job.Status == "PENDING" {
+			pending = append(pending, job)
+		}
+	}
+	if len(pending) == 0 {
+		return fmt.Errorf("failed to create job group: %w", ErrEmptyGroup)
+	}
+
+	now := time.Now()
+	group.ID = uuid.New()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	stored := *group
+	s.groups[group.ID] = &stored
+	s.nextSeq(group.ID)
+
+	for _, job := range pending {
+		gid := group.ID
+		job.JobGroupID = &gid
+		job.Status = "SCHEDULED"
+		job.UpdatedAt = now
+	}
+	return nil
+}
+
+func (s *MemoryStore) IssueFence(ctx context.Context, key string) (Fence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fences[key]++
+	return Fence{Key: key, Token: s.fences[key]}, nil
+}
+
+func (s *MemoryStore) ListJobGroups(ctx context.Context, limit int) ([]*JobGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := make([]*JobGroup, 0, len(s.groups))
+	for _, group := range s.groups {
+		copied := *group
+		groups = append(groups, &copied)
+	}
+	sort.Slice(groups, func(i, j int) bool { return s.seq[groups[i].ID] > s.seq[groups[j].ID] })
+	if limit >= 0 && len(groups) > limit {
+		groups = groups[:limit]
+	}
+	return groups, nil
+}
+
+func (s *MemoryStore) GetJobGroup(ctx context.Context, id uuid.UUID) (*JobGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get job group: %w", ErrNotFound)
+	}
+	copied := *group
+	return &copied, nil
+}
+
+func (s *MemoryStore) ListGroupJobs(ctx context.Context, groupID uuid.UUID) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.filterJobs(func(job *Job) bool { return job.JobGroupID != nil && *job.JobGroupID == groupID })
+	s.sortByPriority(jobs)
+	return jobs, nil
+}
+
+// ListChildJobs returns the device jobs of a matrix, by device.
+func (s *MemoryStore) ListChildJobs(ctx context.Context, parentID uuid.UUID) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.filterJobs(func(job *Job) bool { return job.ParentJobID != nil && *job.ParentJobID == parentID })
+	sort.Slice(jobs, func(i, j int) bool { return *jobs[i].Device < *jobs[j].Device })
+	return jobs, nil
+}
+
+// rollupGroup recomputes a group's status from its jobs. Callers hold s.mu.
+func (s *MemoryStore) rollupGroup(groupID *uuid.UUID) {
+	if groupID == nil {
+		return
+	}
+	group, ok := s.groups[*groupID]
+	if !ok {
+		return
+	}
+
+	counts := make(map[string]int64)
+	for _, job := range s.jobs {
+		if job.JobGroupID != nil && *job.JobGroupID == *groupID {
+			counts[job.Status]++
+		}
+	}
+
+	status := groupStatus(counts)
+	if status == group.Status {
+		return
+	}
+	now := time.Now()
+	group.Status = status
+	group.UpdatedAt = now
+	group.CompletedAt = nil
+	if groupFinished(status) {
+		group.CompletedAt = &now
+	}
+}
+
+func (s *MemoryStore) ListDispatchableGroups(ctx context.Context) ([]*JobGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	waiting := make(map[uuid.UUID]bool)
+	for _, job := range s.jobs {
+		if job.Status == "SCHEDULED" && job.JobGroupID != nil {
+			waiting[*job.JobGroupID] = true
+		}
+	}
+
+	groups := make([]*JobGroup, 0, len(waiting))
+	for id := range waiting {
+		if group, ok := s.groups[id]; ok {
+			copied := *group
+			groups = append(groups, &copied)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return s.seq[groups[i].ID] < s.seq[groups[j].ID] })
+	return groups, nil
+}
+
+// Agent operations
+func (s *MemoryStore) CreateAgent(ctx context.Context, agent *Agent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.agents {
+		if existing.Hostname == agent.Hostname {
+			return fmt.Errorf("failed to create agent: %w", ErrDuplicate)
+		}
+	}
+
+	now := time.Now()
+	agent.ID = uuid.New()
+	agent.CreatedAt = now
+	agent.UpdatedAt = now
+	agent.LastHeartbeatAt = now
+	if agent.Status == "" {
+		agent.Status = "IDLE"
+	}
+
+	stored := *agent
+	s.agents[agent.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) UpdateAgent(ctx context.Context, agent *Agent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.agents[agent.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now()
+	stored.TargetCapability = agent.TargetCapability
+	stored.Status = agent.Status
+	stored.Labels = agent.Labels
+	stored.LastHeartbeatAt = now
+	stored.UpdatedAt = now
+	*agent = *stored
+	return nil
+}
+
+func (s *MemoryStore) GetAgent(ctx context.Context, id uuid.UUID) (*Agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *agent
+	return &copied, nil
+}
+
+func (s *MemoryStore) GetAgentByHostname(ctx context.Context, hostname string) (*Agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, agent := range s.agents {
+		if agent.Hostname == hostname {
+			copied := *agent
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) UpdateAgentHeartbeat(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if agent, ok := s.agents[id]; ok {
+		agent.LastHeartbeatAt = time.Now()
+	}
+	return nil
+}
+
+func (s *MemoryStore) UpdateAgentStatus(ctx context.Context, id uuid.UUID, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[id]
+	if !ok {
+		return ErrNotFound
+	}
+	agent.Status = status
+	agent.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *MemoryStore) GetAvailableAgents(ctx context.Context, targetCapability string) ([]*Agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-5 * time.Minute)
+	var agents []*Agent
+	for _, agent := range s.agents {
+		if agent.TargetCapability == targetCapability && agent.Status == "IDLE" && agent.LastHeartbeatAt.After(cutoff) {
+			copied := *agent
+			agents = append(agents, &copied)
+		}
+	}
+	return agents, nil
+}
+
+func (s *MemoryStore) ListAgents(ctx context.Context) ([]*Agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agents := make([]*Agent, 0, len(s.agents))
+	for _, agent := range s.agents {
+		copied := *agent
+		agents = append(agents, &copied)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].LastHeartbeatAt.After(agents[j].LastHeartbeatAt) })
+	return agents, nil
+}
+
+// filterJobs returns copies of the jobs matching keep. Callers hold s.mu.
+func (s *MemoryStore) filterJobs(keep func(*Job) bool) []*Job {
+	var jobs []*Job
+	for _, job := range s.jobs {
+		if keep(job) {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	return jobs
+}
+
+// sortByPriority orders jobs the way the scheduler and FetchJob expect:
+// highest priority first, then oldest first.
+func (s *MemoryStore) sortByPriority(jobs []*Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority > jobs[j].Priority
+		}
+		return s.seq[jobs[i].ID] < s.seq[jobs[j].ID]
+	})
+}
+
+func limitJobs(jobs []*Job, limit int) []*Job {
+	if limit >= 0 && len(jobs) > limit {
+		return jobs[:limit]
+	}
+	return jobs
+}
+
+// MemoryCache is an in-process QueueStore and CacheStore for development
+// and tests, standing in for Redis. Keys expire lazily on access.
+type MemoryCache struct {
+	mu     sync.Mutex
+	values map[string]memoryValue
+	queues map[string][]uuid.UUID
+	pushed chan struct{}
+	swept  time.Time
+}
+
+type memoryValue struct {
+	value   string
+	expires time.Time // zero means no expiry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		values: make(map[string]memoryValue),
+		queues: make(map[string][]uuid.UUID),
+		pushed: make(chan struct{}),
+		swept:  time.Now(),
+	}
+}
+
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Queue operations
+func (c *MemoryCache) PushToIngestionQueue(ctx context.Context, jobID uuid.UUID) error {
+	c.push("ingestion_queue", jobID)
+	return nil
+}
+
+func (c *MemoryCache) PopFromIngestionQueue(ctx context.Context, timeout time.Duration) (uuid.UUID, error) {
+	jobID, err := c.pop(ctx, "ingestion_queue", timeout)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to pop from ingestion queue: %w", err)
+	}
+	return jobID, nil
+}
+
+func (c *MemoryCache) PushToDispatchQueue(ctx context.Context, target string, groupID uuid.UUID) error {
+	c.push(fmt.Sprintf("dispatch_queue:%s", target), groupID)
+	return nil
+}
+
+func (c *MemoryCache) PopFromDispatchQueue(ctx context.Context, target string, timeout time.Duration) (uuid.UUID, error) {
+	groupID, err := c.pop(ctx, fmt.Sprintf("dispatch_queue:%s", target), timeout)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to pop from dispatch queue: %w", err)
+	}
+	return groupID, nil
+}
+
+func (c *MemoryCache) ListDispatchQueue(ctx context.Context, target string) ([]uuid.UUID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uuid.UUID(nil), c.queues[fmt.Sprintf("dispatch_queue:%s", target)]...), nil
+}
+
+func (c *MemoryCache) ListIngestionQueue(ctx context.Context) ([]uuid.UUID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uuid.UUID(nil), c.queues["ingestion_queue"]...), nil
+}
+
+func (c *MemoryCache) GetIngestionQueueLength(ctx context.Context) (int64, error) {
+	return c.queueLength("ingestion_queue"), nil
+}
+
+func (c *MemoryCache) GetDispatchQueueLength(ctx context.Context, target string) (int64, error) {
+	return c.queueLength(fmt.Sprintf("dispatch_queue:%s", target)), nil
+}
+
+func (c *MemoryCache) push(queue string, id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.queues[queue] = append(c.queues[queue], id)
+	// Wake every waiting pop; the ones that lose the race wait again
+	close(c.pushed)
+	c.pushed = make(chan struct{})
+}
+
+// pop removes the oldest entry, waiting up to timeout for one to arrive.
+// A zero timeout waits until ctx is done, like BRPOP.
+func (c *MemoryCache) pop(ctx context.Context, queue string, timeout time.Duration) (uuid.UUID, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		c.mu.Lock()
+		if items := c.queues[queue]; len(items) > 0 {
+			id := items[0]
+			c.queues[queue] = items[1:]
+			c.mu.Unlock()
+			return id, nil
+		}
+		pushed := c.pushed
+		c.mu.Unlock()
+
+		select {
+		case <-pushed:
+		case <-deadline:
+			return uuid.Nil, ErrQueueEmpty
+		case <-ctx.Done():
+			return uuid.Nil, ctx.Err()
+		}
+	}
+}
+
+// takeQueues empties every queue and returns the entries, oldest first.
+func (c *MemoryCache) takeQueues() map[string][]uuid.UUID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	taken := c.queues
+	c.queues = make(map[string][]uuid.UUID)
+	return taken
+}
+
+func (c *MemoryCache) queueLength(queue string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(len(c.queues[queue]))
+}
+
+// Key/value operations
+func (c *MemoryCache) set(key, value string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry := memoryValue{value: value}
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
+	c.values[key] = entry
+
+	// Drop expired keys now and then so unread entries don't pile up
+	if now.Sub(c.swept) > time.Minute {
+		for k, v := range c.values {
+			if !v.expires.IsZero() && now.After(v.expires) {
+				delete(c.values, k)
+			}
+		}
+		c.swept = now
+	}
+}
+
+func (c *MemoryCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(key)
+}
+
+func (c *MemoryCache) getLocked(key string) (string, bool) {
+	entry, ok := c.values[key]
+	if !ok {
+		return "", false
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(c.values, key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+func (c *MemoryCache) del(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, key)
+}
+
+// Distributed lock operations
+func (c *MemoryCache) AcquireLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, held := c.getLocked(lockKey); held {
+		return false, nil
+	}
+	c.values[lockKey] = memoryValue{value: owner, expires: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (c *MemoryCache) RenewLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if holder, held := c.getLocked(lockKey); !held || holder != owner {
+		return false, nil
+	}
+	c.values[lockKey] = memoryValue{value: owner, expires: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (c *MemoryCache) ReleaseLock(ctx context.Context, lockKey, owner string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if holder, held := c.getLocked(lockKey); held && holder == owner {
+		delete(c.values, lockKey)
+	}
+	return nil
+}
+
+// Scheduler control operations
+func (c *MemoryCache) SetSchedulerPaused(ctx context.Context, paused bool) error {
+	if paused {
+		c.set(schedulerPausedKey, "paused", 0)
+	} else {
+		c.del(schedulerPausedKey)
+	}
+	return nil
+}
+
+func (c *MemoryCache) IsSchedulerPaused(ctx context.Context) (bool, error) {
+	_, paused := c.get(schedulerPausedKey)
+	return paused, nil
+}
+
+// Heartbeat operations
+func (c *MemoryCache) UpdateAgentHeartbeat(ctx context.Context, agentID uuid.UUID, ttl time.Duration) error {
+	c.set(fmt.Sprintf("agent:heartbeat:%s", agentID.String()), "alive", ttl)
+	return nil
+}
+
+func (c *MemoryCache) RemoveAgentHeartbeat(ctx context.Context, agentID uuid.UUID) error {
+	c.del(fmt.Sprintf("agent:heartbeat:%s", agentID.String()))
+	return nil
+}
+
+func (c *MemoryCache) IsAgentAlive(ctx context.Context, agentID uuid.UUID) (bool, error) {
+	_, alive := c.get(fmt.Sprintf("agent:heartbeat:%s", agentID.String()))
+	return alive, nil
+}
+
+// Idempotency operations
+func (c *MemoryCache) CheckIdempotency(ctx context.Context, key string) (bool, error) {
+	_, exists := c.get(fmt.Sprintf("idempotency:%s", key))
+	return exists, nil
+}
+
+func (c *MemoryCache) SetIdempotency(ctx context.Context, key string, ttl time.Duration) error {
+	c.set(fmt.Sprintf("idempotency:%s", key), "processed", ttl)
+	return nil
+}
+
+// Cache operations
+func (c *MemoryCache) SetJobStatus(ctx context.Context, jobID uuid.UUID, status string, ttl time.Duration) error {
+	c.set(fmt.Sprintf("job:status:%s", jobID.String()), status, ttl)
+	return nil
+}
+
+func (c *MemoryCache) GetJobStatus(ctx context.Context, jobID uuid.UUID) (string, error) {
+	status, ok := c.get(fmt.Sprintf("job:status:%s", jobID.String()))
+	if !ok {
+		return "", fmt.Errorf("failed to get job status from cache: %w", ErrNotFound)
+	}
+	return status, nil
+}
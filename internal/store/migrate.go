@@ -0,0 +1,313 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationFS embed.FS
+
+// ErrSchemaMismatch is returned when the database schema version differs
+// from the version this binary was built for.
+var ErrSchemaMismatch = errors.New("schema version mismatch")
+
+// Migration is one versioned schema change. Files are named
+// NNNN_name.up.sql and NNNN_name.down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus pairs a migration with when it was applied, if ever.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// migrationDialect holds what differs between databases when migrating.
+type migrationDialect struct {
+	dir            string
+	createTable    string
+	insertVersion  string
+	deleteVersion  string
+	lock           func(ctx context.Context, conn *sql.Conn) error
+	unlock         func(conn *sql.Conn)
+	undefinedTable func(err error) bool
+}
+
+// migrator implements schema migrations for a store; PostgresStore and
+// SQLiteStore embed it.
+type migrator struct {
+	db      *sql.DB
+	dialect migrationDialect
+}
+
+// Migrations returns the embedded migrations in version order.
+func (m *migrator) Migrations() ([]Migration, error) {
+	return loadMigrations(migrationFS, m.dialect.dir)
+}
+
+// LatestSchemaVersion is the schema version this binary expects.
+func (m *migrator) LatestSchemaVersion() int {
+	migrations, err := m.Migrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		prefix, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", name)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be contiguous from 1, found %d at position %d", m.Version, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// queryer is satisfied by *sql.DB, *sql.Conn and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// SchemaVersion returns the highest applied migration, or 0 for a database
+// that has never been migrated.
+func (m *migrator) SchemaVersion(ctx context.Context) (int, error) {
+	return m.schemaVersion(ctx, m.db)
+}
+
+func (m *migrator) schemaVersion(ctx context.Context, q queryer) (int, error) {
+	var version int
+	err := q.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil && m.dialect.undefinedTable(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// CheckSchema returns ErrSchemaMismatch unless every embedded migration, and
+// nothing newer, has been applied.
+func (m *migrator) CheckSchema(ctx context.Context) error {
+	current, err := m.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if expected := m.LatestSchemaVersion(); current != expected {
+		return fmt.Errorf("%w: database is at version %d, this binary expects %d", ErrSchemaMismatch, current, expected)
+	}
+	return nil
+}
+
+// MigrationStatus lists every embedded migration and whether it is applied.
+func (m *migrator) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil && !m.dialect.undefinedTable(err) {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var appliedAt time.Time
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan migration: %w", err)
+			}
+			applied[version] = appliedAt
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, mig := range migrations {
+		status := MigrationStatus{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// MigrateUp applies every pending migration in order, each in its own
+// transaction, and returns the ones it applied.
+func (m *migrator) MigrateUp(ctx context.Context) ([]Migration, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = m.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.schemaVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range migrations {
+			if mig.Version <= current {
+				continue
+			}
+			err := runMigration(ctx, conn, mig.Up, m.dialect.insertVersion, mig.Version, mig.Name, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts the most recent steps migrations and returns them in
+// the order they were reverted.
+func (m *migrator) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	err = m.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.schemaVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current > len(migrations) {
+			return fmt.Errorf("%w: database is at version %d, newer than this binary knows how to revert", ErrSchemaMismatch, current)
+		}
+		for version := current; version > 0 && len(reverted) < steps; version-- {
+			mig := migrations[version-1]
+			err := runMigration(ctx, conn, mig.Down, m.dialect.deleteVersion, mig.Version)
+			if err != nil {
+				return fmt.Errorf("failed to revert migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// withMigrationLock runs fn on a dedicated connection holding the dialect's
+// migration lock, creating the schema_migrations table if needed.
+func (m *migrator) withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != nil {
+		if err := m.dialect.lock(ctx, conn); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer m.dialect.unlock(conn)
+	}
+
+	if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// runMigration executes a migration body and its bookkeeping statement in
+// one transaction.
+func runMigration(ctx context.Context, conn *sql.Conn, body, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return tx.Commit()
+}
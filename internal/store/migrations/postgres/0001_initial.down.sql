@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS test_results;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS job_groups;
+DROP TABLE IF EXISTS agents;
+DROP FUNCTION IF EXISTS update_updated_at_column();
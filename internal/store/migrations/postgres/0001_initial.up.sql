@@ -1,4 +1,7 @@
--- Database schema for QualGent Test Platform
+-- Initial schema for QualGent Test Platform.
+--
+-- Written to be safe against databases created from the old schema.sql,
+-- so existing deployments can adopt migrations by running "migrate up".
 
 -- Jobs table - stores individual test jobs
 CREATE TABLE IF NOT EXISTS jobs (
@@ -59,8 +62,16 @@ CREATE TABLE IF NOT EXISTS test_results (
 );
 
 -- Add foreign key constraints
-ALTER TABLE jobs ADD CONSTRAINT fk_jobs_job_group_id FOREIGN KEY (job_group_id) REFERENCES job_groups(id);
-ALTER TABLE job_groups ADD CONSTRAINT fk_job_groups_agent_id FOREIGN KEY (agent_id) REFERENCES agents(id);
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_jobs_job_group_id') THEN
+        ALTER TABLE jobs ADD CONSTRAINT fk_jobs_job_group_id FOREIGN KEY (job_group_id) REFERENCES job_groups(id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_job_groups_agent_id') THEN
+        ALTER TABLE job_groups ADD CONSTRAINT fk_job_groups_agent_id FOREIGN KEY (agent_id) REFERENCES agents(id);
+    END IF;
+END
+$$;
 
 -- Indexes for performance
 CREATE INDEX IF NOT EXISTS idx_jobs_status_priority ON jobs(status, priority);
@@ -82,6 +93,9 @@ END;
 $$ language 'plpgsql';
 
 -- Triggers for updating timestamps
+DROP TRIGGER IF EXISTS update_jobs_updated_at ON jobs;
+DROP TRIGGER IF EXISTS update_job_groups_updated_at ON job_groups;
+DROP TRIGGER IF EXISTS update_agents_updated_at ON agents;
 CREATE TRIGGER update_jobs_updated_at BEFORE UPDATE ON jobs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_job_groups_updated_at BEFORE UPDATE ON job_groups FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-CREATE TRIGGER update_agents_updated_at BEFORE UPDATE ON agents FOR EACH ROW EXECUTE FUNCTION update_updated_at_column(); 
\ No newline at end of file
+CREATE TRIGGER update_agents_updated_at BEFORE UPDATE ON agents FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS test_type;
+ALTER TABLE jobs DROP COLUMN IF EXISTS web_app_url;
@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS span_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS trace_id;
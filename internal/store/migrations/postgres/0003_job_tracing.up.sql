@@ -0,0 +1,4 @@
+-- Tracing: span the job was submitted under, so the scheduler and agent
+-- can continue the same trace.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS trace_id TEXT;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS span_id TEXT;
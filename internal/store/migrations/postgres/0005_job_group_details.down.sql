@@ -0,0 +1,3 @@
+ALTER TABLE job_groups DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE job_groups DROP COLUMN IF EXISTS test_type;
+ALTER TABLE job_groups DROP COLUMN IF EXISTS web_app_url;
@@ -0,0 +1,5 @@
+-- Web groups keep the URL and test framework they were grouped on, and
+-- groups record when their last job finished.
+ALTER TABLE job_groups ADD COLUMN IF NOT EXISTS web_app_url TEXT;
+ALTER TABLE job_groups ADD COLUMN IF NOT EXISTS test_type TEXT;
+ALTER TABLE job_groups ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
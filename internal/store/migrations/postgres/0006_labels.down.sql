@@ -0,0 +1,5 @@
+ALTER TABLE job_groups DROP COLUMN IF EXISTS preferred_labels;
+ALTER TABLE job_groups DROP COLUMN IF EXISTS required_labels;
+ALTER TABLE jobs DROP COLUMN IF EXISTS preferred_labels;
+ALTER TABLE jobs DROP COLUMN IF EXISTS required_labels;
+ALTER TABLE agents DROP COLUMN IF EXISTS labels;
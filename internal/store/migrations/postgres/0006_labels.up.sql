@@ -0,0 +1,7 @@
+-- Agents advertise capability labels; jobs and their groups carry the
+-- labels they require of an agent and the ones they prefer.
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS labels JSONB;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS required_labels JSONB;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS preferred_labels JSONB;
+ALTER TABLE job_groups ADD COLUMN IF NOT EXISTS required_labels JSONB;
+ALTER TABLE job_groups ADD COLUMN IF NOT EXISTS preferred_labels JSONB;
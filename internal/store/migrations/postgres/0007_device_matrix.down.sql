@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_parent_job_id;
+ALTER TABLE job_groups DROP COLUMN IF EXISTS device;
+ALTER TABLE jobs DROP COLUMN IF EXISTS device;
+ALTER TABLE jobs DROP COLUMN IF EXISTS parent_job_id;
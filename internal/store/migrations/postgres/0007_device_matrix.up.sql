@@ -0,0 +1,7 @@
+-- A job submitted for a device matrix fans out into one child job per
+-- device, sharing a parent_job_id that names the matrix as a whole. Groups
+-- keep the device they were grouped on.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS parent_job_id UUID;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS device TEXT;
+ALTER TABLE job_groups ADD COLUMN IF NOT EXISTS device TEXT;
+CREATE INDEX IF NOT EXISTS idx_jobs_parent_job_id ON jobs(parent_job_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS test_results;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS job_groups;
+DROP TABLE IF EXISTS agents;
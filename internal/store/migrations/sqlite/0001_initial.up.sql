@@ -0,0 +1,72 @@
+-- Initial schema for QualGent Test Platform on SQLite.
+--
+-- UUIDs are stored as TEXT and timestamps as UTC TIMESTAMP text written by
+-- the store; there are no updated_at triggers, the store sets it directly.
+
+-- Jobs table - stores individual test jobs
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    org_id TEXT NOT NULL,
+    app_version_id TEXT NOT NULL,
+    test_path TEXT NOT NULL,
+    priority INTEGER DEFAULT 0,
+    target TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    job_group_id TEXT REFERENCES job_groups(id),
+    idempotency_key TEXT UNIQUE,
+    -- Test result fields
+    session_id TEXT,
+    logs_url TEXT,
+    video_url TEXT,
+    error_message TEXT,
+    test_duration INTEGER, -- in seconds
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
+
+-- Job groups table - groups jobs by app_version_id and target
+CREATE TABLE IF NOT EXISTS job_groups (
+    id TEXT PRIMARY KEY,
+    app_version_id TEXT NOT NULL,
+    target TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'SCHEDULED',
+    agent_id TEXT REFERENCES agents(id),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Agents table - stores execution agents
+CREATE TABLE IF NOT EXISTS agents (
+    id TEXT PRIMARY KEY,
+    hostname TEXT UNIQUE NOT NULL,
+    target_capability TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'IDLE',
+    last_heartbeat_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Test results table - detailed test execution results
+CREATE TABLE IF NOT EXISTS test_results (
+    id TEXT PRIMARY KEY,
+    job_id TEXT NOT NULL REFERENCES jobs(id),
+    session_id TEXT,
+    status TEXT NOT NULL, -- passed, failed, error, timeout
+    logs_url TEXT,
+    video_url TEXT,
+    screenshots TEXT, -- JSON array of screenshot URLs
+    error_details TEXT, -- JSON error information
+    test_duration INTEGER, -- in seconds
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Indexes for performance
+CREATE INDEX IF NOT EXISTS idx_jobs_status_priority ON jobs(status, priority);
+CREATE INDEX IF NOT EXISTS idx_jobs_app_version_id ON jobs(app_version_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_job_group_id ON jobs(job_group_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_session_id ON jobs(session_id);
+CREATE INDEX IF NOT EXISTS idx_job_groups_status ON job_groups(status);
+CREATE INDEX IF NOT EXISTS idx_job_groups_app_version_target ON job_groups(app_version_id, target);
+CREATE INDEX IF NOT EXISTS idx_test_results_job_id ON test_results(job_id);
+CREATE INDEX IF NOT EXISTS idx_test_results_session_id ON test_results(session_id);
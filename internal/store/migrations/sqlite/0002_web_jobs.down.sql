@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP COLUMN test_type;
+ALTER TABLE jobs DROP COLUMN web_app_url;
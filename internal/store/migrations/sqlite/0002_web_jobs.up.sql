@@ -0,0 +1,4 @@
+-- Web jobs run against a URL instead of an app build and record the
+-- test framework, both of which the scheduler groups on.
+ALTER TABLE jobs ADD COLUMN web_app_url TEXT;
+ALTER TABLE jobs ADD COLUMN test_type TEXT;
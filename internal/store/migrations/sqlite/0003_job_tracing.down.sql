@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP COLUMN span_id;
+ALTER TABLE jobs DROP COLUMN trace_id;
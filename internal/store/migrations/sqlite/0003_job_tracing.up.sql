@@ -0,0 +1,4 @@
+-- Tracing: span the job was submitted under, so the scheduler and agent
+-- can continue the same trace.
+ALTER TABLE jobs ADD COLUMN trace_id TEXT;
+ALTER TABLE jobs ADD COLUMN span_id TEXT;
@@ -0,0 +1,6 @@
+-- Fencing tokens: the latest token issued per lock, checked by writes made
+-- under the lock.
+CREATE TABLE IF NOT EXISTS lock_fences (
+    key TEXT PRIMARY KEY,
+    token INTEGER NOT NULL
+);
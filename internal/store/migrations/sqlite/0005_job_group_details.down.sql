@@ -0,0 +1,3 @@
+ALTER TABLE job_groups DROP COLUMN completed_at;
+ALTER TABLE job_groups DROP COLUMN test_type;
+ALTER TABLE job_groups DROP COLUMN web_app_url;
@@ -0,0 +1,5 @@
+-- Web groups keep the URL and test framework they were grouped on, and
+-- groups record when their last job finished.
+ALTER TABLE job_groups ADD COLUMN web_app_url TEXT;
+ALTER TABLE job_groups ADD COLUMN test_type TEXT;
+ALTER TABLE job_groups ADD COLUMN completed_at TIMESTAMP;
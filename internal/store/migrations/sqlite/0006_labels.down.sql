@@ -0,0 +1,5 @@
+ALTER TABLE job_groups DROP COLUMN preferred_labels;
+ALTER TABLE job_groups DROP COLUMN required_labels;
+ALTER TABLE jobs DROP COLUMN preferred_labels;
+ALTER TABLE jobs DROP COLUMN required_labels;
+ALTER TABLE agents DROP COLUMN labels;
@@ -0,0 +1,8 @@
+-- Agents advertise capability labels; jobs and their groups carry the
+-- labels they require of an agent and the ones they prefer. Labels are
+-- JSON objects.
+ALTER TABLE agents ADD COLUMN labels TEXT;
+ALTER TABLE jobs ADD COLUMN required_labels TEXT;
+ALTER TABLE jobs ADD COLUMN preferred_labels TEXT;
+ALTER TABLE job_groups ADD COLUMN required_labels TEXT;
+ALTER TABLE job_groups ADD COLUMN preferred_labels TEXT;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_parent_job_id;
+ALTER TABLE job_groups DROP COLUMN device;
+ALTER TABLE jobs DROP COLUMN device;
+ALTER TABLE jobs DROP COLUMN parent_job_id;
@@ -3,7 +3,11 @@ package store
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,8 +15,60 @@ import (
 	"github.com/lib/pq"
 )
 
+// ErrNotFound is returned when an operation targets a row that doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrQueueEmpty is returned when a blocking queue pop times out.
+var ErrQueueEmpty = errors.New("queue is empty")
+
+// ErrDuplicate is returned when a unique key, such as a job's idempotency
+// key, is already taken.
+var ErrDuplicate = errors.New("already exists")
+
+// ErrFenced is returned when a write carries a fencing token older than the
+// latest one issued for its lock.
+var ErrFenced = errors.New("fencing token is stale")
+
+// ErrEmptyGroup is returned by CreateJobGroup when none of the group's jobs
+// are still PENDING, e.g. after an operator requeued or failed them, so no
+// group is created.
+var ErrEmptyGroup = errors.New("no pending jobs to group")
+
 type PostgresStore struct {
 	db *sql.DB
+	migrator
+
+	// Advisory locks are session-level, so each one keeps its connection
+	locksMu sync.Mutex
+	locks   map[string]*sql.Conn
+}
+
+// postgresMigrationLockID is the advisory lock held while migrating, so two
+// job-server instances never apply the same migration concurrently.
+const postgresMigrationLockID = 7205301
+
+var postgresMigrations = migrationDialect{
+	dir: "migrations/postgres",
+	createTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`,
+	insertVersion: `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+	deleteVersion: `DELETE FROM schema_migrations WHERE version = $1`,
+	lock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, postgresMigrationLockID)
+		return err
+	},
+	unlock: func(conn *sql.Conn) {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, postgresMigrationLockID)
+	},
+	undefinedTable: func(err error) bool {
+		var pqErr *pq.Error
+		return errors.As(err, &pqErr) && pqErr.Code == "42P01"
+	},
 }
 
 type Job struct {
@@ -35,6 +91,15 @@ type Job struct {
 	CompletedAt    *time.Time `json:"completed_at,omitempty"`
 	WebAppURL      *string    `json:"web_app_url,omitempty"` // New field
 	TestType       *string    `json:"test_type,omitempty"`   // New field
+	TraceID        *string    `json:"trace_id,omitempty"`
+	SpanID         *string    `json:"span_id,omitempty"`
+	// Labels the agent running the job must have, and ones it should have
+	RequiredLabels  Labels `json:"required_labels,omitempty"`
+	PreferredLabels Labels `json:"preferred_labels,omitempty"`
+	// Jobs fanned out of a device matrix share the ID the matrix was
+	// submitted as, and each runs on its device, e.g. "Pixel 7:13"
+	ParentJobID *uuid.UUID `json:"parent_job_id,omitempty"`
+	Device      *string    `json:"device,omitempty"`
 }
 
 type JobGroup struct {
@@ -43,8 +108,43 @@ type JobGroup struct {
 	Target       string     `json:"target"`
 	Status       string     `json:"status"`
 	AgentID      *uuid.UUID `json:"agent_id,omitempty"`
+	WebAppURL    *string    `json:"web_app_url,omitempty"`
+	TestType     *string    `json:"test_type,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	// Grouped jobs share their label constraints and device
+	RequiredLabels  Labels  `json:"required_labels,omitempty"`
+	PreferredLabels Labels  `json:"preferred_labels,omitempty"`
+	Device          *string `json:"device,omitempty"`
+}
+
+const jobGroupColumns = `id, app_version_id, target, status, agent_id, web_app_url, test_type, created_at, updated_at, completed_at, required_labels, preferred_labels, device`
+
+func scanJobGroup(row interface{ Scan(...interface{}) error }) (*JobGroup, error) {
+	group := &JobGroup{}
+	err := row.Scan(
+		&group.ID, &group.AppVersionID, &group.Target, &group.Status, &group.AgentID,
+		&group.WebAppURL, &group.TestType, &group.CreatedAt, &group.UpdatedAt, &group.CompletedAt,
+		&group.RequiredLabels, &group.PreferredLabels, &group.Device,
+	)
+	return group, err
+}
+
+// scanStatusCounts reads (status, count) rows and closes them.
+func scanStatusCounts(rows *sql.Rows) (map[string]int64, error) {
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan job count: %w", err)
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
 }
 
 type Agent struct {
@@ -55,6 +155,19 @@ type Agent struct {
 	LastHeartbeatAt  time.Time `json:"last_heartbeat_at"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
+	Labels           Labels    `json:"labels,omitempty"`
+}
+
+// Targets returns the targets the agent runs jobs for. TargetCapability
+// holds one target, or several separated by commas.
+func (a *Agent) Targets() []string {
+	var targets []string
+	for _, target := range strings.Split(a.TargetCapability, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
 }
 
 func NewPostgresStore(connStr string) (*PostgresStore, error) {
@@ -67,29 +180,67 @@ func NewPostgresStore(connStr string) (*PostgresStore, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PostgresStore{db: db}, nil
+	return &PostgresStore{
+		db:       db,
+		migrator: migrator{db: db, dialect: postgresMigrations},
+		locks:    make(map[string]*sql.Conn),
+	}, nil
 }
 
 func (s *PostgresStore) Close() error {
 	return s.db.Close()
 }
 
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
 // Job operations
 func (s *PostgresStore) CreateJob(ctx context.Context, job *Job) error {
+	return createPostgresJob(ctx, s.db, job)
+}
+
+// CreateJobs creates several jobs in one transaction, such as the device
+// jobs of a matrix, so that either all of them exist or none do.
+func (s *PostgresStore) CreateJobs(ctx context.Context, jobs []*Job) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, job := range jobs {
+		if err := createPostgresJob(ctx, tx, job); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit jobs: %w", err)
+	}
+	return nil
+}
+
+func createPostgresJob(ctx context.Context, q queryer, job *Job) error {
 	query := `
-		INSERT INTO jobs (org_id, app_version_id, test_path, priority, target, status, idempotency_key, web_app_url, test_type)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO jobs (org_id, app_version_id, test_path, priority, target, status, idempotency_key, web_app_url, test_type, trace_id, span_id,
+		                  required_labels, preferred_labels, parent_job_id, device)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id, created_at, updated_at
 	`
 
 	var id uuid.UUID
 	var createdAt, updatedAt time.Time
 
-	err := s.db.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		job.OrgID, job.AppVersionID, job.TestPath, job.Priority, job.Target, job.Status, job.IdempotencyKey, job.WebAppURL, job.TestType,
+		job.TraceID, job.SpanID, job.RequiredLabels, job.PreferredLabels, job.ParentJobID, job.Device,
 	).Scan(&id, &createdAt, &updatedAt)
 
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return fmt.Errorf("failed to create job: %w", ErrDuplicate)
+		}
 		return fmt.Errorf("failed to create job: %w", err)
 	}
 
@@ -103,7 +254,7 @@ func (s *PostgresStore) GetJob(ctx context.Context, id uuid.UUID) (*Job, error)
 	query := `
 		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
 		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
-		       web_app_url, test_type
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
 		FROM jobs WHERE id = $1
 	`
 
@@ -112,10 +263,13 @@ func (s *PostgresStore) GetJob(ctx context.Context, id uuid.UUID) (*Job, error)
 		&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
 		&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
 		&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
-		&job.WebAppURL, &job.TestType,
+		&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
 	)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get job: %w", ErrNotFound)
+		}
 		return nil, fmt.Errorf("failed to get job: %w", err)
 	}
 
@@ -123,12 +277,13 @@ func (s *PostgresStore) GetJob(ctx context.Context, id uuid.UUID) (*Job, error)
 }
 
 func (s *PostgresStore) UpdateJobStatus(ctx context.Context, id uuid.UUID, status string) error {
-	query := `UPDATE jobs SET status = $1 WHERE id = $2`
-	_, err := s.db.ExecContext(ctx, query, status, id)
-	if err != nil {
+	query := `UPDATE jobs SET status = $1 WHERE id = $2 RETURNING job_group_id`
+	var groupID *uuid.UUID
+	err := s.db.QueryRowContext(ctx, query, status, id).Scan(&groupID)
+	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("failed to update job status: %w", err)
 	}
-	return nil
+	return s.rollupGroup(ctx, groupID)
 }
 
 func (s *PostgresStore) UpdateJobResult(ctx context.Context, id uuid.UUID, result *JobResult) error {
@@ -137,14 +292,16 @@ func (s *PostgresStore) UpdateJobResult(ctx context.Context, id uuid.UUID, resul
 		SET status = $1, session_id = $2, logs_url = $3, video_url = $4,
 		    error_message = $5, test_duration = $6, completed_at = NOW()
 		WHERE id = $7
+		RETURNING job_group_id
 	`
-	_, err := s.db.ExecContext(ctx, query,
+	var groupID *uuid.UUID
+	err := s.db.QueryRowContext(ctx, query,
 		result.Status, result.SessionID, result.LogsURL, result.VideoURL,
-		result.ErrorMessage, result.TestDuration, id)
-	if err != nil {
+		result.ErrorMessage, result.TestDuration, id).Scan(&groupID)
+	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("failed to update job result: %w", err)
 	}
-	return nil
+	return s.rollupGroup(ctx, groupID)
 }
 
 type JobResult struct {
@@ -158,7 +315,8 @@ type JobResult struct {
 
 func (s *PostgresStore) GetPendingJobs(ctx context.Context, limit int) ([]*Job, error) {
 	query := `
-		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key, created_at, updated_at, web_app_url, test_type
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key, created_at, updated_at, web_app_url, test_type, trace_id, span_id,
+		       required_labels, preferred_labels, parent_job_id, device
 		FROM jobs
 		WHERE status = 'PENDING'
 		ORDER BY priority DESC, created_at ASC
@@ -177,6 +335,7 @@ func (s *PostgresStore) GetPendingJobs(ctx context.Context, limit int) ([]*Job,
 		err := rows.Scan(
 			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
 			&job.JobGroupID, &job.IdempotencyKey, &job.CreatedAt, &job.UpdatedAt, &job.WebAppURL, &job.TestType,
+			&job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
@@ -187,75 +346,251 @@ func (s *PostgresStore) GetPendingJobs(ctx context.Context, limit int) ([]*Job,
 	return jobs, nil
 }
 
-func (s *PostgresStore) GetNextJob(ctx context.Context, targetCapability string) (*Job, error) {
+// GetSubmittedJobs returns jobs still waiting for ingestion, oldest first.
+func (s *PostgresStore) GetSubmittedJobs(ctx context.Context, limit int) ([]*Job, error) {
 	query := `
-		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key, created_at, updated_at, web_app_url, test_type
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key, created_at, updated_at, web_app_url, test_type, trace_id, span_id
 		FROM jobs
-		WHERE status = 'SCHEDULED' AND target = $1
-		ORDER BY priority DESC, created_at ASC
-		LIMIT 1
+		WHERE status = 'SUBMITTED'
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submitted jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.CreatedAt, &job.UpdatedAt, &job.WebAppURL, &job.TestType,
+			&job.TraceID, &job.SpanID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// AcceptJob saves the fields ingestion may have enriched and makes a
+// SUBMITTED job PENDING. It returns ErrNotFound if the job is no longer
+// SUBMITTED.
+func (s *PostgresStore) AcceptJob(ctx context.Context, job *Job) error {
+	query := `
+		UPDATE jobs
+		SET status = 'PENDING', app_version_id = $1, test_path = $2, priority = $3
+		WHERE id = $4 AND status = 'SUBMITTED'
+	`
+	result, err := s.db.ExecContext(ctx, query, job.AppVersionID, job.TestPath, job.Priority, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to accept job: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("failed to accept job: %w", ErrNotFound)
+	}
+	job.Status = "PENDING"
+	return nil
+}
+
+// ClaimGroupJob atomically moves the highest-priority SCHEDULED job in a
+// group to ASSIGNED and returns it, so concurrent fetches never hand out
+// the same job twice. more reports whether the group has other SCHEDULED
+// jobs left. It returns a nil job when nothing in the group is waiting.
+func (s *PostgresStore) ClaimGroupJob(ctx context.Context, groupID uuid.UUID) (*Job, bool, error) {
+	query := `
+		UPDATE jobs SET status = 'ASSIGNED'
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'SCHEDULED' AND job_group_id = $1
+			ORDER BY priority DESC, created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key, created_at, updated_at, web_app_url, test_type, trace_id, span_id,
+			parent_job_id, device,
+			EXISTS (SELECT 1 FROM jobs other WHERE other.job_group_id = $1 AND other.status = 'SCHEDULED' AND other.id <> jobs.id)
 	`
 
 	job := &Job{}
-	err := s.db.QueryRowContext(ctx, query, targetCapability).Scan(
+	var more bool
+	err := s.db.QueryRowContext(ctx, query, groupID).Scan(
 		&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
 		&job.JobGroupID, &job.IdempotencyKey, &job.CreatedAt, &job.UpdatedAt, &job.WebAppURL, &job.TestType,
+		&job.TraceID, &job.SpanID, &job.ParentJobID, &job.Device, &more,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil // No job available
+			return nil, false, nil // No job available
 		}
-		return nil, fmt.Errorf("failed to get next job: %w", err)
+		return nil, false, fmt.Errorf("failed to claim group job: %w", err)
 	}
 
-	return job, nil
+	if err := s.rollupGroup(ctx, &groupID); err != nil {
+		return nil, false, err
+	}
+	return job, more, nil
+}
+
+// ClaimGroup assigns a group to agentID and moves all its SCHEDULED jobs
+// to ASSIGNED in one transaction, so the agent can install the app once and
+// run the whole group. Jobs come back in priority order. It returns a nil
+// group when the group is already assigned or has no jobs waiting.
+func (s *PostgresStore) ClaimGroup(ctx context.Context, groupID, agentID uuid.UUID) (*JobGroup, []*Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE job_groups SET agent_id = $1 WHERE id = $2 AND agent_id IS NULL RETURNING ` + jobGroupColumns
+	group, err := scanJobGroup(tx.QueryRowContext(ctx, query, agentID, groupID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to claim job group: %w", err)
+	}
+
+	query = `
+		WITH claimed AS (
+			UPDATE jobs SET status = 'ASSIGNED'
+			WHERE job_group_id = $1 AND status = 'SCHEDULED'
+			RETURNING id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key, created_at, updated_at, web_app_url, test_type, trace_id, span_id,
+			          parent_job_id, device
+		)
+		SELECT * FROM claimed ORDER BY priority DESC, created_at ASC
+	`
+	rows, err := tx.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim group jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.CreatedAt, &job.UpdatedAt, &job.WebAppURL, &job.TestType,
+			&job.TraceID, &job.SpanID, &job.ParentJobID, &job.Device,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to claim group jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		// Polling agents took every job; leave the group unassigned
+		return nil, nil, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit group claim: %w", err)
+	}
+	if err := s.rollupGroup(ctx, &groupID); err != nil {
+		return nil, nil, err
+	}
+	return group, jobs, nil
 }
 
 // JobGroup operations
-func (s *PostgresStore) CreateJobGroup(ctx context.Context, group *JobGroup) error {
+
+// CreateJobGroup creates group and moves the given jobs into it in one
+// transaction. Jobs that are no longer PENDING are left alone. It fails
+// with ErrFenced if fence is stale; the share lock on the fence row holds
+// off a newer holder's IssueFence until the transaction ends.
+func (s *PostgresStore) CreateJobGroup(ctx context.Context, group *JobGroup, jobIDs []uuid.UUID, fence Fence) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current int64
+	err = tx.QueryRowContext(ctx, `SELECT token FROM lock_fences WHERE key = $1 FOR SHARE`, fence.Key).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check fencing token: %w", err)
+	}
+	if current != fence.Token {
+		return fmt.Errorf("failed to create job group: %w", ErrFenced)
+	}
+
 	query := `
-		INSERT INTO job_groups (app_version_id, target, status)
-		VALUES ($1, $2, $3)
+		INSERT INTO job_groups (app_version_id, target, status, web_app_url, test_type, required_labels, preferred_labels, device)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at
 	`
 
 	var id uuid.UUID
 	var createdAt, updatedAt time.Time
 
-	err := s.db.QueryRowContext(ctx, query, group.AppVersionID, group.Target, group.Status).Scan(&id, &createdAt, &updatedAt)
+	err = tx.QueryRowContext(ctx, query, group.AppVersionID, group.Target, group.Status, group.WebAppURL, group.TestType,
+		group.RequiredLabels, group.PreferredLabels, group.Device).Scan(&id, &createdAt, &updatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create job group: %w", err)
 	}
 
+	query = `UPDATE jobs SET job_group_id = $1, status = 'SCHEDULED' WHERE id = ANY($2) AND status = 'PENDING'`
+	result, err := tx.ExecContext(ctx, query, id, pq.Array(jobIDs))
+	if err != nil {
+		return fmt.Errorf("failed to update jobs to group: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("failed to create job group: %w", ErrEmptyGroup)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit job group: %w", err)
+	}
+
 	group.ID = id
 	group.CreatedAt = createdAt
 	group.UpdatedAt = updatedAt
 	return nil
 }
 
-func (s *PostgresStore) UpdateJobsToGroup(ctx context.Context, jobIDs []uuid.UUID, groupID uuid.UUID) error {
-	query := `UPDATE jobs SET job_group_id = $1, status = 'SCHEDULED' WHERE id = ANY($2)`
-	_, err := s.db.ExecContext(ctx, query, groupID, pq.Array(jobIDs))
-	if err != nil {
-		return fmt.Errorf("failed to update jobs to group: %w", err)
+// IssueFence issues the next fencing token for the lock named key.
+func (s *PostgresStore) IssueFence(ctx context.Context, key string) (Fence, error) {
+	query := `
+		INSERT INTO lock_fences (key, token) VALUES ($1, 1)
+		ON CONFLICT (key) DO UPDATE SET token = lock_fences.token + 1
+		RETURNING token
+	`
+	fence := Fence{Key: key}
+	if err := s.db.QueryRowContext(ctx, query, key).Scan(&fence.Token); err != nil {
+		return Fence{}, fmt.Errorf("failed to issue fencing token: %w", err)
 	}
-	return nil
+	return fence, nil
 }
 
 // Agent operations
 func (s *PostgresStore) CreateAgent(ctx context.Context, agent *Agent) error {
 	query := `
-		INSERT INTO agents (hostname, target_capability, status)
-		VALUES ($1, $2, $3)
+		INSERT INTO agents (hostname, target_capability, status, labels)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at, updated_at
 	`
 
 	var id uuid.UUID
 	var createdAt, updatedAt time.Time
 
-	err := s.db.QueryRowContext(ctx, query, agent.Hostname, agent.TargetCapability, agent.Status).Scan(&id, &createdAt, &updatedAt)
+	err := s.db.QueryRowContext(ctx, query, agent.Hostname, agent.TargetCapability, agent.Status, agent.Labels).Scan(&id, &createdAt, &updatedAt)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return fmt.Errorf("failed to create agent: %w", ErrDuplicate)
+		}
 		return fmt.Errorf("failed to create agent: %w", err)
 	}
 
@@ -265,6 +600,26 @@ func (s *PostgresStore) CreateAgent(ctx context.Context, agent *Agent) error {
 	return nil
 }
 
+// UpdateAgent re-registers an existing agent with agent's targets, status
+// and labels.
+func (s *PostgresStore) UpdateAgent(ctx context.Context, agent *Agent) error {
+	query := `
+		UPDATE agents
+		SET target_capability = $1, status = $2, labels = $3, last_heartbeat_at = NOW(), updated_at = NOW()
+		WHERE id = $4
+		RETURNING last_heartbeat_at, created_at, updated_at
+	`
+	err := s.db.QueryRowContext(ctx, query, agent.TargetCapability, agent.Status, agent.Labels, agent.ID).
+		Scan(&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+	return nil
+}
+
 func (s *PostgresStore) UpdateAgentHeartbeat(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE agents SET last_heartbeat_at = NOW() WHERE id = $1`
 	_, err := s.db.ExecContext(ctx, query, id)
@@ -276,7 +631,7 @@ func (s *PostgresStore) UpdateAgentHeartbeat(ctx context.Context, id uuid.UUID)
 
 func (s *PostgresStore) GetAvailableAgents(ctx context.Context, targetCapability string) ([]*Agent, error) {
 	query := `
-		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
 		FROM agents
 		WHERE target_capability = $1 AND status = 'IDLE' AND last_heartbeat_at > NOW() - INTERVAL '5 minutes'
 	`
@@ -292,7 +647,249 @@ func (s *PostgresStore) GetAvailableAgents(ctx context.Context, targetCapability
 		agent := &Agent{}
 		err := rows.Scan(
 			&agent.ID, &agent.Hostname, &agent.TargetCapability, &agent.Status,
-			&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt,
+			&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt, &agent.Labels,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan agent: %w", err)
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, nil
+}
+// Listing operations
+func (s *PostgresStore) ListJobs(ctx context.Context, limit int) ([]*Job, error) {
+	query := `
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
+		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
+		FROM jobs
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
+			&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+			&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (s *PostgresStore) ListJobGroups(ctx context.Context, limit int) ([]*JobGroup, error) {
+	query := `
+		SELECT ` + jobGroupColumns + `
+		FROM job_groups
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*JobGroup
+	for rows.Next() {
+		group, err := scanJobGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (s *PostgresStore) GetJobGroup(ctx context.Context, id uuid.UUID) (*JobGroup, error) {
+	query := `SELECT ` + jobGroupColumns + ` FROM job_groups WHERE id = $1`
+	group, err := scanJobGroup(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get job group: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get job group: %w", err)
+	}
+	return group, nil
+}
+
+// ListGroupJobs returns the jobs in a group in dispatch order.
+func (s *PostgresStore) ListGroupJobs(ctx context.Context, groupID uuid.UUID) ([]*Job, error) {
+	query := `
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
+		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
+		FROM jobs
+		WHERE job_group_id = $1
+		ORDER BY priority DESC, created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
+			&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+			&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// ListChildJobs returns the device jobs of a matrix, by device.
+func (s *PostgresStore) ListChildJobs(ctx context.Context, parentID uuid.UUID) ([]*Job, error) {
+	query := `
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
+		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
+		FROM jobs
+		WHERE parent_job_id = $1
+		ORDER BY device ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
+			&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+			&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// rollupGroup recomputes a group's status from its jobs after one of them
+// changed. Locking the group row first serializes rollups, so the last one
+// counts every job update committed before it.
+func (s *PostgresStore) rollupGroup(ctx context.Context, groupID *uuid.UUID) error {
+	if groupID == nil {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM job_groups WHERE id = $1 FOR UPDATE`, *groupID).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to lock job group: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT status, COUNT(*) FROM jobs WHERE job_group_id = $1 GROUP BY status`, *groupID)
+	if err != nil {
+		return fmt.Errorf("failed to count group jobs: %w", err)
+	}
+	counts, err := scanStatusCounts(rows)
+	if err != nil {
+		return err
+	}
+
+	status := groupStatus(counts)
+	if status == current {
+		return nil
+	}
+	query := `UPDATE job_groups SET status = $1, completed_at = CASE WHEN $2 THEN NOW() END WHERE id = $3`
+	if _, err := tx.ExecContext(ctx, query, status, groupFinished(status), *groupID); err != nil {
+		return fmt.Errorf("failed to update job group status: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListDispatchableGroups returns groups that still have SCHEDULED jobs,
+// oldest first.
+func (s *PostgresStore) ListDispatchableGroups(ctx context.Context) ([]*JobGroup, error) {
+	query := `
+		SELECT ` + jobGroupColumns + `
+		FROM job_groups g
+		WHERE EXISTS (SELECT 1 FROM jobs j WHERE j.job_group_id = g.id AND j.status = 'SCHEDULED')
+		ORDER BY created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dispatchable job groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*JobGroup
+	for rows.Next() {
+		group, err := scanJobGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (s *PostgresStore) ListAgents(ctx context.Context) ([]*Agent, error) {
+	query := `
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
+		FROM agents
+		ORDER BY last_heartbeat_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*Agent
+	for rows.Next() {
+		agent := &Agent{}
+		err := rows.Scan(
+			&agent.ID, &agent.Hostname, &agent.TargetCapability, &agent.Status,
+			&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt, &agent.Labels,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan agent: %w", err)
@@ -301,4 +898,204 @@ func (s *PostgresStore) GetAvailableAgents(ctx context.Context, targetCapability
 	}
 
 	return agents, nil
-}
\ No newline at end of file
+}
+
+// Admin operations
+func (s *PostgresStore) RequeueJob(ctx context.Context, id uuid.UUID) error {
+	requeued, err := s.requeueJob(ctx, id, "")
+	if err == nil && !requeued {
+		return ErrNotFound
+	}
+	return err
+}
+
+// RequeueUnfinishedJob requeues a job unless it has already COMPLETED or
+// FAILED, reporting whether it did. The check is part of the update, so a
+// final status landing at the same time is never overwritten.
+func (s *PostgresStore) RequeueUnfinishedJob(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.requeueJob(ctx, id, "AND status NOT IN ('COMPLETED', 'FAILED')")
+}
+
+// requeueJob resets a job to PENDING if it matches cond, reporting whether
+// it did.
+func (s *PostgresStore) requeueJob(ctx context.Context, id uuid.UUID, cond string) (bool, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'PENDING', job_group_id = NULL, session_id = NULL, logs_url = NULL, video_url = NULL,
+		    error_message = NULL, test_duration = NULL, completed_at = NULL
+		FROM (SELECT id, job_group_id FROM jobs WHERE id = $1 ` + cond + ` FOR UPDATE) old
+		WHERE jobs.id = old.id
+		RETURNING old.job_group_id
+	`
+	var groupID *uuid.UUID
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&groupID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to requeue job: %w", err)
+	}
+	return true, s.rollupGroup(ctx, groupID)
+}
+
+func (s *PostgresStore) RequeueAgentJobs(ctx context.Context, agentID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'PENDING', job_group_id = NULL
+		FROM (
+			SELECT j.id, j.job_group_id FROM jobs j
+			JOIN job_groups g ON g.id = j.job_group_id
+			WHERE g.agent_id = $1 AND j.status IN ('SCHEDULED', 'ASSIGNED', 'RUNNING')
+			FOR UPDATE OF j
+		) old
+		WHERE jobs.id = old.id
+		RETURNING old.job_group_id
+	`
+	rows, err := s.db.QueryContext(ctx, query, agentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue agent jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var n int64
+	groups := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var groupID uuid.UUID
+		if err := rows.Scan(&groupID); err != nil {
+			return 0, fmt.Errorf("failed to scan requeued job: %w", err)
+		}
+		groups[groupID] = true
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to requeue agent jobs: %w", err)
+	}
+
+	for groupID := range groups {
+		if err := s.rollupGroup(ctx, &groupID); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (s *PostgresStore) CountJobsByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM jobs GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	return scanStatusCounts(rows)
+}
+
+func (s *PostgresStore) GetAgent(ctx context.Context, id uuid.UUID) (*Agent, error) {
+	query := `
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
+		FROM agents WHERE id = $1
+	`
+
+	agent := &Agent{}
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&agent.ID, &agent.Hostname, &agent.TargetCapability, &agent.Status,
+		&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt, &agent.Labels,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	return agent, nil
+}
+
+// GetAgentByHostname returns the agent registered as hostname.
+func (s *PostgresStore) GetAgentByHostname(ctx context.Context, hostname string) (*Agent, error) {
+	query := `
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
+		FROM agents WHERE hostname = $1
+	`
+
+	agent := &Agent{}
+	err := s.db.QueryRowContext(ctx, query, hostname).Scan(
+		&agent.ID, &agent.Hostname, &agent.TargetCapability, &agent.Status,
+		&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt, &agent.Labels,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	return agent, nil
+}
+
+func (s *PostgresStore) UpdateAgentStatus(ctx context.Context, id uuid.UUID, status string) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE agents SET status = $1 WHERE id = $2`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update agent status: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Advisory lock operations
+
+// TryLock takes the session-level advisory lock named key without waiting.
+// The lock lives on a dedicated connection until Unlock. Postgres releases
+// it when that session ends, so a crashed holder never leaves it stuck, and
+// a connection whose lock may still be held is closed rather than pooled.
+func (s *PostgresStore) TryLock(ctx context.Context, key string) (bool, error) {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+
+	if _, held := s.locks[key]; held {
+		return false, nil
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, key).Scan(&acquired); err != nil {
+		// The lock may have been taken before the error
+		discardConn(conn)
+		return false, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	s.locks[key] = conn
+	return true, nil
+}
+
+func (s *PostgresStore) Unlock(ctx context.Context, key string) error {
+	s.locksMu.Lock()
+	conn, held := s.locks[key]
+	delete(s.locks, key)
+	s.locksMu.Unlock()
+
+	if !held {
+		return nil
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, key); err != nil {
+		// Pooled, the idle connection would go on holding the lock
+		discardConn(conn)
+		return fmt.Errorf("failed to release advisory lock: %w", err)
+	}
+	return conn.Close()
+}
+
+// discardConn closes conn's session instead of returning it to the pool,
+// which releases any advisory locks it holds.
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(any) error { return driver.ErrBadConn })
+	conn.Close()
+}
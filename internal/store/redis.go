@@ -32,6 +32,10 @@ func (s *RedisStore) Close() error {
 	return s.client.Close()
 }
 
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
 // Queue operations
 func (s *RedisStore) PushToIngestionQueue(ctx context.Context, jobID uuid.UUID) error {
 	return s.client.LPush(ctx, "ingestion_queue", jobID.String()).Err()
@@ -39,6 +43,9 @@ func (s *RedisStore) PushToIngestionQueue(ctx context.Context, jobID uuid.UUID)
 
 func (s *RedisStore) PopFromIngestionQueue(ctx context.Context, timeout time.Duration) (uuid.UUID, error) {
 	result, err := s.client.BRPop(ctx, timeout, "ingestion_queue").Result()
+	if err == redis.Nil {
+		return uuid.Nil, fmt.Errorf("failed to pop from ingestion queue: %w", ErrQueueEmpty)
+	}
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to pop from ingestion queue: %w", err)
 	}
@@ -63,6 +70,9 @@ func (s *RedisStore) PushToDispatchQueue(ctx context.Context, target string, gro
 func (s *RedisStore) PopFromDispatchQueue(ctx context.Context, target string, timeout time.Duration) (uuid.UUID, error) {
 	queueName := fmt.Sprintf("dispatch_queue:%s", target)
 	result, err := s.client.BRPop(ctx, timeout, queueName).Result()
+	if err == redis.Nil {
+		return uuid.Nil, fmt.Errorf("failed to pop from dispatch queue: %w", ErrQueueEmpty)
+	}
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to pop from dispatch queue: %w", err)
 	}
@@ -79,14 +89,104 @@ func (s *RedisStore) PopFromDispatchQueue(ctx context.Context, target string, ti
 	return groupID, nil
 }
 
+// ListIngestionQueue returns the job IDs in the ingestion queue, next to be
+// popped first.
+func (s *RedisStore) ListIngestionQueue(ctx context.Context) ([]uuid.UUID, error) {
+	jobIDs, err := s.listQueue(ctx, "ingestion_queue")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingestion queue: %w", err)
+	}
+	return jobIDs, nil
+}
+
+// ListDispatchQueue returns the group IDs in a dispatch queue, next to be
+// popped first.
+func (s *RedisStore) ListDispatchQueue(ctx context.Context, target string) ([]uuid.UUID, error) {
+	groupIDs, err := s.listQueue(ctx, fmt.Sprintf("dispatch_queue:%s", target))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dispatch queue: %w", err)
+	}
+	return groupIDs, nil
+}
+
+// listQueue reads a whole queue without popping it. Pushes go to the head
+// and pops take the tail, so the entries are reversed.
+func (s *RedisStore) listQueue(ctx context.Context, queueName string) ([]uuid.UUID, error) {
+	result, err := s.client.LRange(ctx, queueName, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(result))
+	for i := len(result) - 1; i >= 0; i-- {
+		id, err := uuid.Parse(result[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse queue entry: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 // Distributed lock operations
-func (s *RedisStore) AcquireLock(ctx context.Context, lockKey string, ttl time.Duration) (bool, error) {
-	result := s.client.SetNX(ctx, lockKey, "locked", ttl)
+
+// The lock key holds its owner, so only the owner can renew or release it;
+// these scripts compare and act in one step.
+var (
+	renewLockScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		end
+		return 0
+	`)
+	releaseLockScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0
+	`)
+)
+
+func (s *RedisStore) AcquireLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error) {
+	result := s.client.SetNX(ctx, lockKey, owner, ttl)
 	return result.Val(), result.Err()
 }
 
-func (s *RedisStore) ReleaseLock(ctx context.Context, lockKey string) error {
-	return s.client.Del(ctx, lockKey).Err()
+// RenewLock extends the lock's TTL if owner still holds it, and reports
+// whether it does.
+func (s *RedisStore) RenewLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error) {
+	renewed, err := renewLockScript.Run(ctx, s.client, []string{lockKey}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lock: %w", err)
+	}
+	return renewed == 1, nil
+}
+
+// ReleaseLock deletes the lock only if owner still holds it, so a holder
+// whose lock expired can't release the next holder's.
+func (s *RedisStore) ReleaseLock(ctx context.Context, lockKey, owner string) error {
+	if err := releaseLockScript.Run(ctx, s.client, []string{lockKey}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// Scheduler control operations
+const schedulerPausedKey = "scheduler:paused"
+
+func (s *RedisStore) SetSchedulerPaused(ctx context.Context, paused bool) error {
+	if paused {
+		return s.client.Set(ctx, schedulerPausedKey, "paused", 0).Err()
+	}
+	return s.client.Del(ctx, schedulerPausedKey).Err()
+}
+
+func (s *RedisStore) IsSchedulerPaused(ctx context.Context) (bool, error) {
+	exists, err := s.client.Exists(ctx, schedulerPausedKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check scheduler pause: %w", err)
+	}
+	return exists > 0, nil
 }
 
 // Heartbeat operations
@@ -95,6 +195,11 @@ func (s *RedisStore) UpdateAgentHeartbeat(ctx context.Context, agentID uuid.UUID
 	return s.client.Set(ctx, key, "alive", ttl).Err()
 }
 
+func (s *RedisStore) RemoveAgentHeartbeat(ctx context.Context, agentID uuid.UUID) error {
+	key := fmt.Sprintf("agent:heartbeat:%s", agentID.String())
+	return s.client.Del(ctx, key).Err()
+}
+
 func (s *RedisStore) IsAgentAlive(ctx context.Context, agentID uuid.UUID) (bool, error) {
 	key := fmt.Sprintf("agent:heartbeat:%s", agentID.String())
 	exists, err := s.client.Exists(ctx, key).Result()
@@ -132,6 +237,16 @@ func (s *RedisStore) GetJobStatus(ctx context.Context, jobID uuid.UUID) (string,
 	return result, nil
 }
 
+// deleteJobStatus drops a cached job status.
+func (s *RedisStore) deleteJobStatus(ctx context.Context, jobID uuid.UUID) error {
+	return s.client.Del(ctx, fmt.Sprintf("job:status:%s", jobID.String())).Err()
+}
+
+// pushRaw pushes onto a queue by its Redis key.
+func (s *RedisStore) pushRaw(ctx context.Context, queueName string, id uuid.UUID) error {
+	return s.client.LPush(ctx, queueName, id.String()).Err()
+}
+
 // Queue statistics
 func (s *RedisStore) GetQueueLength(ctx context.Context, queueName string) (int64, error) {
 	return s.client.LLen(ctx, queueName).Result()
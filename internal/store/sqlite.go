@@ -0,0 +1,945 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLiteStore is a JobStore and AgentStore backed by a single SQLite file,
+// for small deployments that don't want to run Postgres. It stores UUIDs as
+// text and writes every timestamp itself in UTC, so string comparison of
+// timestamps matches time order.
+type SQLiteStore struct {
+	db *sql.DB
+	migrator
+	localLocks
+}
+
+var sqliteMigrations = migrationDialect{
+	dir: "migrations/sqlite",
+	createTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`,
+	insertVersion: `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+	deleteVersion: `DELETE FROM schema_migrations WHERE version = ?`,
+	// SQLite serializes writers itself, and _txlock=immediate makes each
+	// migration transaction take the write lock up front
+	undefinedTable: func(err error) bool {
+		return err != nil && strings.Contains(err.Error(), "no such table")
+	},
+}
+
+// NewSQLiteStore opens (creating if needed) the SQLite database at path.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on&_txlock=immediate", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &SQLiteStore{db: db, migrator: migrator{db: db, dialect: sqliteMigrations}}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// sqliteNow is the timestamp written wherever Postgres would use NOW().
+func sqliteNow() time.Time {
+	return time.Now().UTC()
+}
+
+const sqliteJobColumns = `id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key, created_at, updated_at, web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device`
+
+func scanSQLiteJob(row interface{ Scan(...interface{}) error }) (*Job, error) {
+	job := &Job{}
+	err := row.Scan(
+		&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+		&job.JobGroupID, &job.IdempotencyKey, &job.CreatedAt, &job.UpdatedAt, &job.WebAppURL, &job.TestType,
+		&job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+	)
+	return job, err
+}
+
+// Job operations
+func (s *SQLiteStore) CreateJob(ctx context.Context, job *Job) error {
+	return createSQLiteJob(ctx, s.db, job)
+}
+
+// CreateJobs creates several jobs in one transaction, such as the device
+// jobs of a matrix, so that either all of them exist or none do.
+func (s *SQLiteStore) CreateJobs(ctx context.Context, jobs []*Job) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, job := range jobs {
+		if err := createSQLiteJob(ctx, tx, job); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit jobs: %w", err)
+	}
+	return nil
+}
+
+func createSQLiteJob(ctx context.Context, q queryer, job *Job) error {
+	query := `
+		INSERT INTO jobs (id, org_id, app_version_id, test_path, priority, target, status, idempotency_key, web_app_url, test_type, trace_id, span_id,
+		                  required_labels, preferred_labels, parent_job_id, device, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id := uuid.New()
+	createdAt := sqliteNow()
+	_, err := q.ExecContext(ctx, query,
+		id, job.OrgID, job.AppVersionID, job.TestPath, job.Priority, job.Target, job.Status, job.IdempotencyKey, job.WebAppURL, job.TestType,
+		job.TraceID, job.SpanID, job.RequiredLabels, job.PreferredLabels, job.ParentJobID, job.Device, createdAt, createdAt,
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return fmt.Errorf("failed to create job: %w", ErrDuplicate)
+		}
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	job.ID = id
+	job.CreatedAt = createdAt
+	job.UpdatedAt = createdAt
+	return nil
+}
+
+func (s *SQLiteStore) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	query := `
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
+		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
+		FROM jobs WHERE id = ?
+	`
+
+	job := &Job{}
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+		&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
+		&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+		&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get job: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
+
+// UpdateJobStatus and the other writes that move a job roll its group up in
+// the same transaction; transactions take the write lock when they begin,
+// so rollups never race.
+func (s *SQLiteStore) UpdateJobStatus(ctx context.Context, id uuid.UUID, status string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE jobs SET status = ?, updated_at = ? WHERE id = ? RETURNING job_group_id`
+	var groupID *uuid.UUID
+	err = tx.QueryRowContext(ctx, query, status, sqliteNow(), id).Scan(&groupID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to update job status: %w", err)
+	}
+	if err := rollupSQLiteGroup(ctx, tx, groupID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) UpdateJobResult(ctx context.Context, id uuid.UUID, result *JobResult) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE jobs
+		SET status = ?, session_id = ?, logs_url = ?, video_url = ?,
+		    error_message = ?, test_duration = ?, completed_at = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING job_group_id
+	`
+	completedAt := sqliteNow()
+	var groupID *uuid.UUID
+	err = tx.QueryRowContext(ctx, query,
+		result.Status, result.SessionID, result.LogsURL, result.VideoURL,
+		result.ErrorMessage, result.TestDuration, completedAt, completedAt, id).Scan(&groupID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to update job result: %w", err)
+	}
+	if err := rollupSQLiteGroup(ctx, tx, groupID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rollupSQLiteGroup recomputes a group's status from its jobs inside tx.
+func rollupSQLiteGroup(ctx context.Context, tx *sql.Tx, groupID *uuid.UUID) error {
+	if groupID == nil {
+		return nil
+	}
+
+	var current string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM job_groups WHERE id = ?`, *groupID).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to get job group: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT status, COUNT(*) FROM jobs WHERE job_group_id = ? GROUP BY status`, *groupID)
+	if err != nil {
+		return fmt.Errorf("failed to count group jobs: %w", err)
+	}
+	counts, err := scanStatusCounts(rows)
+	if err != nil {
+		return err
+	}
+
+	status := groupStatus(counts)
+	if status == current {
+		return nil
+	}
+	now := sqliteNow()
+	var completedAt *time.Time
+	if groupFinished(status) {
+		completedAt = &now
+	}
+	query := `UPDATE job_groups SET status = ?, completed_at = ?, updated_at = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, status, completedAt, now, *groupID); err != nil {
+		return fmt.Errorf("failed to update job group status: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetPendingJobs(ctx context.Context, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + sqliteJobColumns + `
+		FROM jobs
+		WHERE status = 'PENDING'
+		ORDER BY priority DESC, created_at ASC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanSQLiteJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (s *SQLiteStore) GetSubmittedJobs(ctx context.Context, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + sqliteJobColumns + `
+		FROM jobs
+		WHERE status = 'SUBMITTED'
+		ORDER BY created_at ASC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submitted jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanSQLiteJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (s *SQLiteStore) AcceptJob(ctx context.Context, job *Job) error {
+	query := `
+		UPDATE jobs
+		SET status = 'PENDING', app_version_id = ?, test_path = ?, priority = ?, updated_at = ?
+		WHERE id = ? AND status = 'SUBMITTED'
+	`
+	result, err := s.db.ExecContext(ctx, query, job.AppVersionID, job.TestPath, job.Priority, sqliteNow(), job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to accept job: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("failed to accept job: %w", ErrNotFound)
+	}
+	job.Status = "PENDING"
+	return nil
+}
+
+// ClaimGroupJob is the SQLite counterpart of the Postgres SKIP LOCKED claim.
+// SQLite has one writer at a time, so an UPDATE ... RETURNING that re-checks
+// the status picks and claims the job atomically; the transaction keeps the
+// check for other waiting jobs consistent with the claim.
+func (s *SQLiteStore) ClaimGroupJob(ctx context.Context, groupID uuid.UUID) (*Job, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE jobs SET status = 'ASSIGNED', updated_at = ?
+		WHERE status = 'SCHEDULED' AND id = (
+			SELECT id FROM jobs
+			WHERE status = 'SCHEDULED' AND job_group_id = ?
+			ORDER BY priority DESC, created_at ASC
+			LIMIT 1
+		)
+		RETURNING ` + sqliteJobColumns
+
+	job, err := scanSQLiteJob(tx.QueryRowContext(ctx, query, sqliteNow(), groupID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil // No job available
+		}
+		return nil, false, fmt.Errorf("failed to claim group job: %w", err)
+	}
+
+	var more bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM jobs WHERE job_group_id = ? AND status = 'SCHEDULED')`, groupID,
+	).Scan(&more)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check group for waiting jobs: %w", err)
+	}
+
+	if err := rollupSQLiteGroup(ctx, tx, &groupID); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit job claim: %w", err)
+	}
+	return job, more, nil
+}
+
+// ClaimGroup assigns a group to agentID and moves all its SCHEDULED jobs
+// to ASSIGNED in one transaction. It returns a nil group when the group is
+// already assigned or has no jobs waiting.
+func (s *SQLiteStore) ClaimGroup(ctx context.Context, groupID, agentID uuid.UUID) (*JobGroup, []*Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := sqliteNow()
+	query := `UPDATE job_groups SET agent_id = ?, updated_at = ? WHERE id = ? AND agent_id IS NULL RETURNING ` + jobGroupColumns
+	group, err := scanJobGroup(tx.QueryRowContext(ctx, query, agentID, now, groupID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to claim job group: %w", err)
+	}
+
+	// The transaction holds the write lock, so the jobs read here are the
+	// ones the update below claims
+	query = `SELECT ` + sqliteJobColumns + ` FROM jobs WHERE job_group_id = ? AND status = 'SCHEDULED' ORDER BY priority DESC, created_at ASC`
+	rows, err := tx.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list group jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanSQLiteJob(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		job.Status = "ASSIGNED"
+		job.UpdatedAt = now
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list group jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		// Polling agents took every job; leave the group unassigned
+		return nil, nil, nil
+	}
+
+	query = `UPDATE jobs SET status = 'ASSIGNED', updated_at = ? WHERE job_group_id = ? AND status = 'SCHEDULED'`
+	if _, err := tx.ExecContext(ctx, query, now, groupID); err != nil {
+		return nil, nil, fmt.Errorf("failed to claim group jobs: %w", err)
+	}
+
+	if err := rollupSQLiteGroup(ctx, tx, &groupID); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit group claim: %w", err)
+	}
+	return group, jobs, nil
+}
+
+// JobGroup operations
+
+// CreateJobGroup writes first and checks the fence last: the first write
+// takes SQLite's single write lock, so no token can be issued between the
+// check and the commit.
+func (s *SQLiteStore) CreateJobGroup(ctx context.Context, group *JobGroup, jobIDs []uuid.UUID, fence Fence) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO job_groups (id, app_version_id, target, status, web_app_url, test_type, required_labels, preferred_labels, device, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id := uuid.New()
+	createdAt := sqliteNow()
+	_, err = tx.ExecContext(ctx, query, id, group.AppVersionID, group.Target, group.Status, group.WebAppURL, group.TestType,
+		group.RequiredLabels, group.PreferredLabels, group.Device, createdAt, createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job group: %w", err)
+	}
+
+	if len(jobIDs) == 0 {
+		return fmt.Errorf("failed to create job group: %w", ErrEmptyGroup)
+	}
+
+	// Expand the ID list into one placeholder per job, since SQLite has no
+	// array parameters for pq.Array to map onto
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(jobIDs)), ", ")
+	query = `UPDATE jobs SET job_group_id = ?, status = 'SCHEDULED', updated_at = ? WHERE status = 'PENDING' AND id IN (` + placeholders + `)`
+
+	args := make([]interface{}, 0, len(jobIDs)+2)
+	args = append(args, id, createdAt)
+	for _, jobID := range jobIDs {
+		args = append(args, jobID)
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update jobs to group: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("failed to create job group: %w", ErrEmptyGroup)
+	}
+
+	var current int64
+	err = tx.QueryRowContext(ctx, `SELECT token FROM lock_fences WHERE key = ?`, fence.Key).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check fencing token: %w", err)
+	}
+	if current != fence.Token {
+		return fmt.Errorf("failed to create job group: %w", ErrFenced)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit job group: %w", err)
+	}
+
+	group.ID = id
+	group.CreatedAt = createdAt
+	group.UpdatedAt = createdAt
+	return nil
+}
+
+func (s *SQLiteStore) IssueFence(ctx context.Context, key string) (Fence, error) {
+	query := `
+		INSERT INTO lock_fences (key, token) VALUES (?, 1)
+		ON CONFLICT (key) DO UPDATE SET token = token + 1
+		RETURNING token
+	`
+	fence := Fence{Key: key}
+	if err := s.db.QueryRowContext(ctx, query, key).Scan(&fence.Token); err != nil {
+		return Fence{}, fmt.Errorf("failed to issue fencing token: %w", err)
+	}
+	return fence, nil
+}
+
+// Agent operations
+func (s *SQLiteStore) CreateAgent(ctx context.Context, agent *Agent) error {
+	query := `
+		INSERT INTO agents (id, hostname, target_capability, status, labels, last_heartbeat_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id := uuid.New()
+	createdAt := sqliteNow()
+	_, err := s.db.ExecContext(ctx, query, id, agent.Hostname, agent.TargetCapability, agent.Status, agent.Labels, createdAt, createdAt, createdAt)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return fmt.Errorf("failed to create agent: %w", ErrDuplicate)
+		}
+		return fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	agent.ID = id
+	agent.LastHeartbeatAt = createdAt
+	agent.CreatedAt = createdAt
+	agent.UpdatedAt = createdAt
+	return nil
+}
+
+// UpdateAgent re-registers an existing agent with agent's targets, status
+// and labels.
+func (s *SQLiteStore) UpdateAgent(ctx context.Context, agent *Agent) error {
+	query := `
+		UPDATE agents
+		SET target_capability = ?, status = ?, labels = ?, last_heartbeat_at = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING last_heartbeat_at, created_at, updated_at
+	`
+	now := sqliteNow()
+	err := s.db.QueryRowContext(ctx, query, agent.TargetCapability, agent.Status, agent.Labels, now, now, agent.ID).
+		Scan(&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) UpdateAgentHeartbeat(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE agents SET last_heartbeat_at = ?, updated_at = ? WHERE id = ?`
+	heartbeatAt := sqliteNow()
+	_, err := s.db.ExecContext(ctx, query, heartbeatAt, heartbeatAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update agent heartbeat: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetAvailableAgents(ctx context.Context, targetCapability string) ([]*Agent, error) {
+	query := `
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
+		FROM agents
+		WHERE target_capability = ? AND status = 'IDLE' AND last_heartbeat_at > ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, targetCapability, sqliteNow().Add(-5*time.Minute))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available agents: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSQLiteAgents(rows)
+}
+
+// Listing operations
+func (s *SQLiteStore) ListJobs(ctx context.Context, limit int) ([]*Job, error) {
+	query := `
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
+		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
+		FROM jobs
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
+			&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+			&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (s *SQLiteStore) ListJobGroups(ctx context.Context, limit int) ([]*JobGroup, error) {
+	query := `
+		SELECT ` + jobGroupColumns + `
+		FROM job_groups
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*JobGroup
+	for rows.Next() {
+		group, err := scanJobGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (s *SQLiteStore) GetJobGroup(ctx context.Context, id uuid.UUID) (*JobGroup, error) {
+	query := `SELECT ` + jobGroupColumns + ` FROM job_groups WHERE id = ?`
+	group, err := scanJobGroup(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get job group: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get job group: %w", err)
+	}
+	return group, nil
+}
+
+func (s *SQLiteStore) ListGroupJobs(ctx context.Context, groupID uuid.UUID) ([]*Job, error) {
+	query := `
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
+		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
+		FROM jobs
+		WHERE job_group_id = ?
+		ORDER BY priority DESC, created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
+			&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+			&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// ListChildJobs returns the device jobs of a matrix, by device.
+func (s *SQLiteStore) ListChildJobs(ctx context.Context, parentID uuid.UUID) ([]*Job, error) {
+	query := `
+		SELECT id, org_id, app_version_id, test_path, priority, target, status, job_group_id, idempotency_key,
+		       session_id, logs_url, video_url, error_message, test_duration, created_at, updated_at, completed_at,
+		       web_app_url, test_type, trace_id, span_id, required_labels, preferred_labels, parent_job_id, device
+		FROM jobs
+		WHERE parent_job_id = ?
+		ORDER BY device ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		err := rows.Scan(
+			&job.ID, &job.OrgID, &job.AppVersionID, &job.TestPath, &job.Priority, &job.Target, &job.Status,
+			&job.JobGroupID, &job.IdempotencyKey, &job.SessionID, &job.LogsURL, &job.VideoURL,
+			&job.ErrorMessage, &job.TestDuration, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+			&job.WebAppURL, &job.TestType, &job.TraceID, &job.SpanID, &job.RequiredLabels, &job.PreferredLabels, &job.ParentJobID, &job.Device,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// ListDispatchableGroups returns groups that still have SCHEDULED jobs,
+// oldest first.
+func (s *SQLiteStore) ListDispatchableGroups(ctx context.Context) ([]*JobGroup, error) {
+	query := `
+		SELECT ` + jobGroupColumns + `
+		FROM job_groups g
+		WHERE EXISTS (SELECT 1 FROM jobs j WHERE j.job_group_id = g.id AND j.status = 'SCHEDULED')
+		ORDER BY created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dispatchable job groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*JobGroup
+	for rows.Next() {
+		group, err := scanJobGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (s *SQLiteStore) ListAgents(ctx context.Context) ([]*Agent, error) {
+	query := `
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
+		FROM agents
+		ORDER BY last_heartbeat_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSQLiteAgents(rows)
+}
+
+func scanSQLiteAgents(rows *sql.Rows) ([]*Agent, error) {
+	var agents []*Agent
+	for rows.Next() {
+		agent := &Agent{}
+		err := rows.Scan(
+			&agent.ID, &agent.Hostname, &agent.TargetCapability, &agent.Status,
+			&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt, &agent.Labels,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan agent: %w", err)
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, nil
+}
+
+// Admin operations
+func (s *SQLiteStore) RequeueJob(ctx context.Context, id uuid.UUID) error {
+	requeued, err := s.requeueJob(ctx, id, "")
+	if err == nil && !requeued {
+		return ErrNotFound
+	}
+	return err
+}
+
+// RequeueUnfinishedJob requeues a job unless it has already COMPLETED or
+// FAILED, reporting whether it did. The check is part of the update, so a
+// final status landing at the same time is never overwritten.
+func (s *SQLiteStore) RequeueUnfinishedJob(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.requeueJob(ctx, id, "AND status NOT IN ('COMPLETED', 'FAILED')")
+}
+
+// requeueJob resets a job to PENDING if it matches cond, reporting whether
+// it did.
+func (s *SQLiteStore) requeueJob(ctx context.Context, id uuid.UUID, cond string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// RETURNING only sees the new row, so read the group first
+	var groupID *uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT job_group_id FROM jobs WHERE id = ?`, id).Scan(&groupID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to requeue job: %w", err)
+	}
+
+	query := `
+		UPDATE jobs
+		SET status = 'PENDING', job_group_id = NULL, session_id = NULL, logs_url = NULL, video_url = NULL,
+		    error_message = NULL, test_duration = NULL, completed_at = NULL, updated_at = ?
+		WHERE id = ? ` + cond
+	result, err := tx.ExecContext(ctx, query, sqliteNow(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue job: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := rollupSQLiteGroup(ctx, tx, groupID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (s *SQLiteStore) RequeueAgentJobs(ctx context.Context, agentID uuid.UUID) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM job_groups WHERE agent_id = ? AND status IN ('SCHEDULED', 'RUNNING')`, agentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list agent job groups: %w", err)
+	}
+	var groupIDs []uuid.UUID
+	for rows.Next() {
+		var groupID uuid.UUID
+		if err := rows.Scan(&groupID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan job group: %w", err)
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+	rows.Close()
+
+	query := `
+		UPDATE jobs
+		SET status = 'PENDING', job_group_id = NULL, updated_at = ?
+		WHERE status IN ('SCHEDULED', 'ASSIGNED', 'RUNNING')
+		  AND job_group_id IN (SELECT id FROM job_groups WHERE agent_id = ?)
+	`
+	result, err := tx.ExecContext(ctx, query, sqliteNow(), agentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue agent jobs: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return 0, nil
+	}
+
+	for i := range groupIDs {
+		if err := rollupSQLiteGroup(ctx, tx, &groupIDs[i]); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit requeue: %w", err)
+	}
+	return n, nil
+}
+
+func (s *SQLiteStore) CountJobsByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM jobs GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	return scanStatusCounts(rows)
+}
+
+func (s *SQLiteStore) GetAgent(ctx context.Context, id uuid.UUID) (*Agent, error) {
+	query := `
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
+		FROM agents WHERE id = ?
+	`
+
+	agent := &Agent{}
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&agent.ID, &agent.Hostname, &agent.TargetCapability, &agent.Status,
+		&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt, &agent.Labels,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	return agent, nil
+}
+
+// GetAgentByHostname returns the agent registered as hostname.
+func (s *SQLiteStore) GetAgentByHostname(ctx context.Context, hostname string) (*Agent, error) {
+	query := `
+		SELECT id, hostname, target_capability, status, last_heartbeat_at, created_at, updated_at, labels
+		FROM agents WHERE hostname = ?
+	`
+
+	agent := &Agent{}
+	err := s.db.QueryRowContext(ctx, query, hostname).Scan(
+		&agent.ID, &agent.Hostname, &agent.TargetCapability, &agent.Status,
+		&agent.LastHeartbeatAt, &agent.CreatedAt, &agent.UpdatedAt, &agent.Labels,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	return agent, nil
+}
+
+func (s *SQLiteStore) UpdateAgentStatus(ctx context.Context, id uuid.UUID, status string) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE agents SET status = ?, updated_at = ? WHERE id = ?`, status, sqliteNow(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update agent status: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
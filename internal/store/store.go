@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStore persists jobs and the groups the scheduler puts them in.
+type JobStore interface {
+	Ping(ctx context.Context) error
+
+	CreateJob(ctx context.Context, job *Job) error
+	CreateJobs(ctx context.Context, jobs []*Job) error
+	GetJob(ctx context.Context, id uuid.UUID) (*Job, error)
+	UpdateJobStatus(ctx context.Context, id uuid.UUID, status string) error
+	UpdateJobResult(ctx context.Context, id uuid.UUID, result *JobResult) error
+	GetPendingJobs(ctx context.Context, limit int) ([]*Job, error)
+	GetSubmittedJobs(ctx context.Context, limit int) ([]*Job, error)
+	AcceptJob(ctx context.Context, job *Job) error
+	ClaimGroupJob(ctx context.Context, groupID uuid.UUID) (job *Job, more bool, err error)
+	ClaimGroup(ctx context.Context, groupID, agentID uuid.UUID) (*JobGroup, []*Job, error)
+	ListJobs(ctx context.Context, limit int) ([]*Job, error)
+	CountJobsByStatus(ctx context.Context) (map[string]int64, error)
+	RequeueJob(ctx context.Context, id uuid.UUID) error
+	RequeueUnfinishedJob(ctx context.Context, id uuid.UUID) (bool, error)
+	RequeueAgentJobs(ctx context.Context, agentID uuid.UUID) (int64, error)
+
+	CreateJobGroup(ctx context.Context, group *JobGroup, jobIDs []uuid.UUID, fence Fence) error
+	GetJobGroup(ctx context.Context, id uuid.UUID) (*JobGroup, error)
+	ListGroupJobs(ctx context.Context, groupID uuid.UUID) ([]*Job, error)
+	ListChildJobs(ctx context.Context, parentID uuid.UUID) ([]*Job, error)
+	IssueFence(ctx context.Context, key string) (Fence, error)
+	ListJobGroups(ctx context.Context, limit int) ([]*JobGroup, error)
+	ListDispatchableGroups(ctx context.Context) ([]*JobGroup, error)
+}
+
+// Fence is a fencing token issued to the holder of a named lock. Writes
+// that carry one fail with ErrFenced once a newer token has been issued for
+// the same lock, so a holder whose lock expired mid-cycle can't overwrite
+// the work of the next one.
+type Fence struct {
+	Key   string
+	Token int64
+}
+
+// groupStatus rolls the statuses of a group's jobs up into the group's:
+// SCHEDULED until a job is handed out, RUNNING while any job is unfinished,
+// then COMPLETED, FAILED or PARTIAL_FAILURE once all are done. A group
+// whose jobs were all requeued out of it is CANCELLED.
+func groupStatus(counts map[string]int64) string {
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	completed, failed := counts["COMPLETED"], counts["FAILED"]
+
+	switch {
+	case total == 0:
+		return "CANCELLED"
+	case counts["SCHEDULED"] == total:
+		return "SCHEDULED"
+	case completed+failed < total:
+		return "RUNNING"
+	case failed == 0:
+		return "COMPLETED"
+	case completed == 0:
+		return "FAILED"
+	default:
+		return "PARTIAL_FAILURE"
+	}
+}
+
+// groupFinished reports whether a group status is final.
+func groupFinished(status string) bool {
+	switch status {
+	case "COMPLETED", "FAILED", "PARTIAL_FAILURE", "CANCELLED":
+		return true
+	}
+	return false
+}
+
+// AgentStore persists registered agents.
+type AgentStore interface {
+	CreateAgent(ctx context.Context, agent *Agent) error
+	UpdateAgent(ctx context.Context, agent *Agent) error
+	GetAgent(ctx context.Context, id uuid.UUID) (*Agent, error)
+	GetAgentByHostname(ctx context.Context, hostname string) (*Agent, error)
+	UpdateAgentHeartbeat(ctx context.Context, id uuid.UUID) error
+	UpdateAgentStatus(ctx context.Context, id uuid.UUID, status string) error
+	GetAvailableAgents(ctx context.Context, targetCapability string) ([]*Agent, error)
+	ListAgents(ctx context.Context) ([]*Agent, error)
+}
+
+// QueueStore holds the ingestion queue and the per-target dispatch queues.
+type QueueStore interface {
+	PushToIngestionQueue(ctx context.Context, jobID uuid.UUID) error
+	PopFromIngestionQueue(ctx context.Context, timeout time.Duration) (uuid.UUID, error)
+	ListIngestionQueue(ctx context.Context) ([]uuid.UUID, error)
+	PushToDispatchQueue(ctx context.Context, target string, groupID uuid.UUID) error
+	PopFromDispatchQueue(ctx context.Context, target string, timeout time.Duration) (uuid.UUID, error)
+	ListDispatchQueue(ctx context.Context, target string) ([]uuid.UUID, error)
+	GetIngestionQueueLength(ctx context.Context) (int64, error)
+	GetDispatchQueueLength(ctx context.Context, target string) (int64, error)
+}
+
+// CacheStore holds short-lived state: cached job statuses, idempotency
+// markers, agent heartbeats, and scheduler coordination.
+type CacheStore interface {
+	Ping(ctx context.Context) error
+
+	SetJobStatus(ctx context.Context, jobID uuid.UUID, status string, ttl time.Duration) error
+	GetJobStatus(ctx context.Context, jobID uuid.UUID) (string, error)
+	CheckIdempotency(ctx context.Context, key string) (bool, error)
+	SetIdempotency(ctx context.Context, key string, ttl time.Duration) error
+
+	UpdateAgentHeartbeat(ctx context.Context, agentID uuid.UUID, ttl time.Duration) error
+	RemoveAgentHeartbeat(ctx context.Context, agentID uuid.UUID) error
+	IsAgentAlive(ctx context.Context, agentID uuid.UUID) (bool, error)
+
+	AcquireLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error)
+	RenewLock(ctx context.Context, lockKey, owner string, ttl time.Duration) (bool, error)
+	ReleaseLock(ctx context.Context, lockKey, owner string) error
+	SetSchedulerPaused(ctx context.Context, paused bool) error
+	IsSchedulerPaused(ctx context.Context) (bool, error)
+}
+
+// Locker provides exclusive named locks from the job store, used in place
+// of Redis locks while Redis is unavailable.
+type Locker interface {
+	TryLock(ctx context.Context, key string) (bool, error)
+	Unlock(ctx context.Context, key string) error
+}
+
+// localLocks is a process-local Locker for stores that only ever have a
+// single job-server in front of them.
+type localLocks struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func (l *localLocks) TryLock(ctx context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held[key] {
+		return false, nil
+	}
+	if l.held == nil {
+		l.held = make(map[string]bool)
+	}
+	l.held[key] = true
+	return true, nil
+}
+
+func (l *localLocks) Unlock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, key)
+	return nil
+}
+
+// Migrator applies and inspects the versioned schema migrations embedded
+// in the binary. PostgresStore and SQLiteStore implement it.
+type Migrator interface {
+	SchemaVersion(ctx context.Context) (int, error)
+	LatestSchemaVersion() int
+	CheckSchema(ctx context.Context) error
+	MigrationStatus(ctx context.Context) ([]MigrationStatus, error)
+	MigrateUp(ctx context.Context) ([]Migration, error)
+	MigrateDown(ctx context.Context, steps int) ([]Migration, error)
+}
+
+var (
+	_ Migrator = (*PostgresStore)(nil)
+	_ Migrator = (*SQLiteStore)(nil)
+	_ Locker   = (*PostgresStore)(nil)
+	_ Locker   = (*SQLiteStore)(nil)
+
+	_ JobStore   = (*PostgresStore)(nil)
+	_ AgentStore = (*PostgresStore)(nil)
+	_ QueueStore = (*RedisStore)(nil)
+	_ CacheStore = (*RedisStore)(nil)
+
+	_ JobStore   = (*SQLiteStore)(nil)
+	_ AgentStore = (*SQLiteStore)(nil)
+
+	_ JobStore   = (*MemoryStore)(nil)
+	_ AgentStore = (*MemoryStore)(nil)
+	_ QueueStore = (*MemoryCache)(nil)
+	_ CacheStore = (*MemoryCache)(nil)
+
+	_ QueueStore = (*FailoverCache)(nil)
+	_ CacheStore = (*FailoverCache)(nil)
+)
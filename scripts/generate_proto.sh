@@ -12,6 +12,6 @@ protoc --go_out=. \
        --go_opt=paths=source_relative \
        --go-grpc_out=. \
        --go-grpc_opt=paths=source_relative \
-       api/proto/job_service.proto
+       api/proto/*.proto
 
 echo "Protobuf code generated successfully!" 
\ No newline at end of file
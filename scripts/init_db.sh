@@ -14,7 +14,8 @@ DB_NAME=${DB_NAME:-qg_jobs}
 # Create database if it doesn't exist
 PGPASSWORD=$DB_PASSWORD psql -h $DB_HOST -p $DB_PORT -U $DB_USER -d postgres -c "CREATE DATABASE $DB_NAME;" 2>/dev/null || echo "Database $DB_NAME already exists"
 
-# Run schema migration
-PGPASSWORD=$DB_PASSWORD psql -h $DB_HOST -p $DB_PORT -U $DB_USER -d $DB_NAME -f internal/store/schema.sql
+# Apply schema migrations embedded in job-server
+DB_HOST=$DB_HOST DB_PORT=$DB_PORT DB_USER=$DB_USER DB_PASSWORD=$DB_PASSWORD DB_NAME=$DB_NAME \
+    go run ./cmd/job-server migrate up
 
 echo "Database initialized successfully!" 
\ No newline at end of file
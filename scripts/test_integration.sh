@@ -109,9 +109,8 @@ init_database() {
         sleep 1
     done
 
-    echo "Running database schema migration..."
-    docker-compose exec -T postgres psql -U user -d qg_jobs -f /app/internal/store/schema.sql || \
-        print_warning "Database schema migration failed (this might be expected if tables already exist)"
+    echo "Running database schema migrations..."
+    go run ./cmd/job-server migrate up || { print_error "Database schema migration failed"; exit 1; }
     
     print_status "Database initialized"
 }